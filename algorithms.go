@@ -0,0 +1,239 @@
+package shapley
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Algorithm names a way to compute the Shapley values of the rows of a data file.
+type Algorithm string
+
+const (
+	// Dense sums the worths of all 2^n coalitions and the exact values from them, see Solver.Shapley.
+	Dense Algorithm = "dense"
+	// Dividend shares every dividend equally among the players of its coalition, φ_i = Σ_{T∋i} d(T)/|T|,
+	// exact in time linear in the rows, see Solver.ShapleyDividends.
+	Dividend Algorithm = "dividend"
+	// Sampling estimates the values from random orders of the players, see SampleShapley. It holds the rows
+	// as Dividend does and takes longer, so it is only chosen by name.
+	Sampling Algorithm = "sampling"
+)
+
+// Estimate is the approximate peak memory and running time of an algorithm on the rows of a game.
+type Estimate struct {
+	Err       error // why the algorithm can't compute the game, nil when it can
+	Algorithm Algorithm
+	Memory    float64 // bytes
+	Time      time.Duration
+}
+
+// Exact reports whether the algorithm computes the values up to rounding.
+func (e Estimate) Exact() bool {
+	return e.Algorithm != Sampling
+}
+
+// Costs of the estimates, measured on a 64-bit core. The memory of the rows themselves is left out,
+// all the algorithms read them first.
+const (
+	nsRecord   = 200 // parse a row
+	nsHandle   = 7   // the zeta transform of handle and the map of the Game, per coalition and player
+	nsTerm     = 3   // a marginal contribution of the dense sum, per coalition and player
+	nsMember   = 5   // a share of a dividend
+	nsSubset   = 2   // test a row against a sampled coalition
+	bytesWorth = 8   // a worth of a dense table
+	bytesEntry = 40  // a worth of the map of a Game
+	bytesSlice = 24  // the header of the words of a BigCoalition
+)
+
+// EstimateAlgorithms returns the estimates of Dense, Dividend and Sampling with perms permutations
+// and workers goroutines, GOMAXPROCS when workers isn't positive.
+func EstimateAlgorithms(records [][]string, perms, workers int) []Estimate {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var n int
+	var members float64
+	if len(records) > 0 {
+		n = len(strings.Fields(records[len(records)-1][0]))
+	}
+	for _, rec := range records {
+		members += float64(len(strings.Fields(rec[0])))
+	}
+	rows := float64(len(records))
+	coalitions := math.Ldexp(1, n)
+	// the coalition and the dividend of every row of parseDividends
+	rowBytes := rows * (bytesSlice + bytesWorth*float64(1+(n+63)/64))
+
+	dense := Estimate{
+		Algorithm: Dense,
+		// the compensated sums of handle and the worths of the Game, or the worths and the dense table of the chunks
+		Memory: (2*bytesWorth + bytesEntry) * coalitions,
+		Time:   nanoseconds(rows*nsRecord + nsHandle*float64(n)*coalitions + nsTerm*float64(n)*coalitions/float64(workers)),
+	}
+	if n == 0 || n > MaxPlayers {
+		dense.Err = fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+	dividend := Estimate{
+		Algorithm: Dividend,
+		Memory:    rowBytes + 3*bytesWorth*float64(n), // and the compensated sums and the bounds
+		Time:      nanoseconds(rows*nsRecord + members*nsMember),
+	}
+	sampling := Estimate{
+		Algorithm: Sampling,
+		Memory:    rowBytes + 3*bytesWorth*float64(n), // and the means, the squares and the standard errors
+		Time:      nanoseconds(rows*nsRecord + float64(perms)*float64(n+1)*rows*nsSubset),
+	}
+	if perms < 2 {
+		sampling.Err = fmt.Errorf("sampling needs at least 2 permutations, %d", perms)
+	}
+
+	return []Estimate{dense, dividend, sampling}
+}
+
+// ChooseAlgorithm returns the estimate of algo, or of the automatic choice when algo is empty or "auto", within memLimit
+// bytes. The automatic choice is Dense when it fits, as the options of a Solver and a partial result of a canceled
+// computation are Dense only, else the fastest other exact algorithm. It refuses up front when none fits.
+func ChooseAlgorithm(estimates []Estimate, algo Algorithm, memLimit float64) (Estimate, error) {
+	if algo != "" && algo != "auto" {
+		for _, e := range estimates {
+			if e.Algorithm != algo {
+				continue
+			}
+			switch {
+			case e.Err != nil:
+				return e, fmt.Errorf("%s algorithm can't compute the game, %w", algo, e.Err)
+			case e.Memory > memLimit:
+				return e, fmt.Errorf("%s algorithm needs about %s, over the memory limit of %s", algo, FormatBytes(e.Memory), FormatBytes(memLimit))
+			}
+			return e, nil
+		}
+		return Estimate{}, fmt.Errorf("unknown algorithm %q", algo)
+	}
+
+	var fits []Estimate
+	for _, e := range estimates {
+		if e.Err == nil && e.Memory <= memLimit && e.Exact() {
+			fits = append(fits, e)
+		}
+	}
+	if len(fits) == 0 {
+		return Estimate{}, fmt.Errorf("no algorithm computes the game within the memory limit of %s", FormatBytes(memLimit))
+	}
+	sort.SliceStable(fits, func(i, j int) bool {
+		if dense := fits[i].Algorithm == Dense; dense != (fits[j].Algorithm == Dense) {
+			return dense
+		}
+		return fits[i].Time < fits[j].Time
+	})
+
+	return fits[0], nil
+}
+
+// FormatBytes formats a number of bytes with a binary prefix, as 1.5 GiB.
+func FormatBytes(b float64) string {
+	const units = "KMGTPE"
+	if b < 1024 {
+		return fmt.Sprintf("%.0f B", b)
+	}
+	exp := 0
+	for b /= 1024; b >= 1024 && exp < len(units)-1; exp++ {
+		b /= 1024
+	}
+
+	return fmt.Sprintf("%.1f %ciB", b, units[exp])
+}
+
+func nanoseconds(ns float64) time.Duration {
+	if ns >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(ns)
+}
+
+// ShapleyDividends returns the Shapley values of the rows of dividends, φ_i = Σ_{T∋i} d(T)/|T|, for any number
+// of players. The players are those of the grand coalition of the last row, the bounds cover the rounding
+// of the shares and of their compensated sums.
+func (s Solver) ShapleyDividends(records [][]string) (*Result, error) {
+	players, coalitions, dividends, err := parseDividends(records)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make([]neumaier, len(players))
+	errs := make([]float64, len(players))
+	for k, T := range coalitions {
+		members := T.Members()
+		share := dividends[k] / float64(len(members))
+		for _, i := range members {
+			sums[i].add(share)
+			errs[i] += unitRoundoff * math.Abs(share)
+		}
+	}
+
+	res := &Result{Values: make(map[string]float64, len(players)), Bounds: make(map[string]float64, len(players)), Coverage: 1}
+	for i, player := range players {
+		value := sums[i].result()
+		res.Sum += value
+		res.Values[player] = value
+		res.Bounds[player] = errs[i] + 2*unitRoundoff*math.Abs(value)
+	}
+
+	return res, nil
+}
+
+// ShapleySampling estimates the Shapley values of the rows of dividends from perms random orders of the players,
+// v(S) = Σ_{T⊆S} d(T) costs a pass over the rows. The bounds of the result are the standard errors of the values.
+func (s Solver) ShapleySampling(ctx context.Context, records [][]string, perms int, rng *rand.Rand) (*Result, error) {
+	players, coalitions, dividends, err := parseDividends(records)
+	if err != nil {
+		return nil, err
+	}
+	value := func(ctx context.Context, S BigCoalition) (float64, error) {
+		var worth neumaier
+		for k, T := range coalitions {
+			if T.SubsetOf(S) {
+				worth.add(dividends[k])
+			}
+		}
+		return worth.result(), nil
+	}
+
+	sample, err := SampleShapley(ctx, len(players), value, perms, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	return sample.Result(players), nil
+}
+
+// parseDividends returns the sorted players of the grand coalition of the last row and the coalition and dividend
+// of every row.
+func parseDividends(records [][]string) ([]string, []BigCoalition, []float64, error) {
+	players, index, err := rowPlayers(records)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	coalitions := make([]BigCoalition, len(records))
+	dividends := make([]float64, len(records))
+	for k, rec := range records {
+		if coalitions[k], err = parseBigCoalition(rec[0], index); err != nil {
+			return nil, nil, nil, err
+		}
+		dividend, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to convert string to float, %w", err)
+		}
+		dividends[k] = dividend
+	}
+
+	return players, coalitions, dividends, nil
+}
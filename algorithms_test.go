@@ -0,0 +1,108 @@
+package shapley
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_ChooseAlgorithm(t *testing.T) {
+	estimates := EstimateAlgorithms(mockRecords(), 1000, 1)
+	tests := []struct {
+		name     string
+		algo     Algorithm
+		memLimit float64
+		want     Algorithm
+		wantErr  string
+	}{
+		{name: "auto", memLimit: 1 << 30, want: Dense},
+		{name: "auto without room for dense", memLimit: 400, want: Dividend},
+		{name: "dense", algo: Dense, memLimit: 1 << 30, want: Dense},
+		{name: "dense over the limit", algo: Dense, memLimit: 16, wantErr: "over the memory limit"},
+		{name: "auto over the limit", algo: "auto", memLimit: 1, wantErr: "no algorithm"},
+		{name: "unknown", algo: "magic", memLimit: 1 << 30, wantErr: "unknown algorithm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ChooseAlgorithm(estimates, tt.algo, tt.memLimit)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ChooseAlgorithm() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.Algorithm != tt.want {
+				t.Errorf("ChooseAlgorithm() = %v, %v, want %v", got.Algorithm, err, tt.want)
+			}
+		})
+	}
+
+	// 40 players are too many for Dense, the refusal comes before any worth is allocated
+	players := NumberedPlayers("P", 40)
+	records := make([][]string, 0, len(players)+1)
+	for _, player := range players {
+		records = append(records, []string{player, fmt.Sprint(1. / 40)})
+	}
+	records = append(records, []string{strings.Join(players, " "), "0"})
+	estimates = EstimateAlgorithms(records, 100, 0)
+	if _, err := ChooseAlgorithm(estimates, Dense, math.Inf(1)); err == nil {
+		t.Errorf("ChooseAlgorithm() of Dense with 40 players error = nil")
+	}
+	if got, err := ChooseAlgorithm(estimates, "", 1<<30); err != nil || got.Algorithm != Dividend {
+		t.Errorf("ChooseAlgorithm() with 40 players = %v, %v, want dividend", got.Algorithm, err)
+	}
+	if rows := float64(len(records)); estimates[1].Memory < rows*(bytesSlice+bytesWorth) {
+		t.Errorf("EstimateAlgorithms() of dividend = %v bytes, under the coalitions of %v rows", estimates[1].Memory, rows)
+	}
+	res, err := Solver{}.ShapleyDividends(records)
+	if err != nil || notEqualsOne(res.Sum) || math.Abs(res.Values[players[6]]-1./40) > 1e-15 {
+		t.Errorf("ShapleyDividends() with 40 players = %v, %v", res.Values[players[6]], err)
+	}
+}
+
+func Test_ShapleyDividends(t *testing.T) {
+	records, _ := prepare(mockReader())
+	g, _ := ParseGame(records)
+	want, _ := Shapley(g)
+	got, err := Solver{}.ShapleyDividends(records)
+	if err != nil {
+		t.Fatalf("ShapleyDividends() error = %v", err)
+	}
+	for _, player := range g.Players {
+		if diff := math.Abs(got.Values[player] - want.Values[player]); diff > got.Bounds[player]+want.Bounds[player] {
+			t.Errorf("%s: dividends %v ± %v, want %v ± %v", player, got.Values[player], got.Bounds[player],
+				want.Values[player], want.Bounds[player])
+		}
+	}
+
+	if _, err := (Solver{}).ShapleyDividends([][]string{{"A", "1"}, {"C", "1"}, {"A B", "0"}}); err == nil {
+		t.Errorf("ShapleyDividends() of an unknown player error = nil")
+	}
+}
+
+func Test_ShapleySampling(t *testing.T) {
+	got, err := Solver{}.ShapleySampling(context.Background(), mockRecords(), 2000, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("ShapleySampling() error = %v", err)
+	}
+	want := map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335}
+	for player, value := range want {
+		if math.Abs(got.Values[player]-value) > 5*got.Bounds[player]+1e-9 {
+			t.Errorf("%s: sampled %v ± %v, want %v", player, got.Values[player], got.Bounds[player], value)
+		}
+	}
+	if notEqualsOne(got.Sum) {
+		t.Errorf("ShapleySampling() sum = %v, want 1", got.Sum)
+	}
+}
+
+func Test_FormatBytes(t *testing.T) {
+	for b, want := range map[float64]string{512: "512 B", 1536: "1.5 KiB", 1.5 * (1 << 30): "1.5 GiB", 1 << 34: "16.0 GiB"} {
+		if got := FormatBytes(b); got != want {
+			t.Errorf("FormatBytes(%v) = %q, want %q", b, got, want)
+		}
+	}
+}
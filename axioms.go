@@ -0,0 +1,48 @@
+package shapley
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Violation of a Shapley axiom by computed values: gap is how far the values are from satisfying it.
+type Violation struct {
+	Axiom   string
+	Players string
+	Gap     float64
+}
+
+// VerifyAxioms checks sValues of g against the symmetry, null-player and additivity axioms.
+// Efficiency is checked by calc itself. Additivity is tested on a random decomposition v = v1 + v2
+// with v1(S) = u_S·v(S), u_S ~ U(0, 1), drawn from rng.
+func VerifyAxioms(g *Game, sValues map[string]float64, rng *rand.Rand) []Violation {
+	var violations []Violation
+	p := analyzeMarginals(g)
+	for _, pair := range p.Symmetric {
+		a, b := g.Players[pair[0]], g.Players[pair[1]]
+		if gap := math.Abs(sValues[a] - sValues[b]); gap > epsilon {
+			violations = append(violations, Violation{Axiom: "symmetry", Players: a + " ~ " + b, Gap: gap})
+		}
+	}
+	for _, i := range p.Null {
+		if gap := math.Abs(sValues[g.Players[i]]); gap > epsilon {
+			violations = append(violations, Violation{Axiom: "null player", Players: g.Players[i], Gap: gap})
+		}
+	}
+
+	v1, v2 := NewGame(g.Players), NewGame(g.Players)
+	for s := 1; s <= int(g.Grand()); s++ {
+		S := Coalition(s)
+		v1.Worths[S] = rng.Float64() * g.Worths[S]
+		v2.Worths[S] = g.Worths[S] - v1.Worths[S]
+	}
+	s1, _ := shapley(v1.Players, v1.bits(), v1.Worths)
+	s2, _ := shapley(v2.Players, v2.bits(), v2.Worths)
+	for _, player := range g.Players {
+		if gap := math.Abs(s1[player] + s2[player] - sValues[player]); gap > epsilon {
+			violations = append(violations, Violation{Axiom: "additivity", Players: player, Gap: gap})
+		}
+	}
+
+	return violations
+}
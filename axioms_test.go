@@ -0,0 +1,48 @@
+package shapley
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func Test_verifyAxioms(t *testing.T) {
+	majority := &Game{Players: mockPlayers(), Worths: map[Coalition]float64{0b11: 1, 0b101: 1, 0b110: 1, 0b111: 1}}
+	null := &Game{Players: mockPlayers(), Worths: map[Coalition]float64{0b1: 0.5, 0b11: 0.5, 0b101: 1, 0b100: 0.5, 0b110: 0.5, 0b111: 1}}
+	tests := []struct {
+		g      *Game
+		values map[string]float64
+		name   string
+		axioms []string
+	}{
+		{
+			name:   "simple",
+			g:      mockGame(),
+			values: map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335},
+		},
+		{
+			name:   "symmetry",
+			g:      majority,
+			values: map[string]float64{"Google": 0.4, "Meta": 1. / 3, "Microsoft": 0.6 - 1./3},
+			axioms: []string{"symmetry", "symmetry", "symmetry", "additivity", "additivity"},
+		},
+		{
+			name:   "null player",
+			g:      null,
+			values: map[string]float64{"Google": 0.5, "Meta": 0.1, "Microsoft": 0.4},
+			axioms: []string{"symmetry", "null player", "additivity", "additivity"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VerifyAxioms(tt.g, tt.values, rand.New(rand.NewSource(1)))
+			if len(got) != len(tt.axioms) {
+				t.Fatalf("VerifyAxioms() = %v, want %v", got, tt.axioms)
+			}
+			for i, v := range got {
+				if v.Axiom != tt.axioms[i] || v.Gap <= epsilon {
+					t.Errorf("VerifyAxioms()[%d] = %v, want %s", i, v, tt.axioms[i])
+				}
+			}
+		})
+	}
+}
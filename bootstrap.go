@@ -0,0 +1,134 @@
+package shapley
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BootstrapStats summarize the Shapley value of a player over replicate games.
+type BootstrapStats struct {
+	Mean   float64
+	Lo, Hi float64 // percentile confidence interval of the mean
+	TopK   float64 // share of resamples in which the player ranks among the k largest means
+}
+
+// calcFile returns the Shapley values of the game of a data file in the order of its players, they must sum to its v(N).
+func calcFile(path string) (players []string, vector []float64, err error) {
+	g, err := LoadGame(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	res, err := Shapley(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := res.CheckEfficient(g); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	vector = make([]float64, len(g.Players))
+	for i, player := range g.Players {
+		vector[i] = res.Values[player]
+	}
+
+	return g.Players, vector, nil
+}
+
+// ShapleyReplicates runs calcFile on every path with at most GOMAXPROCS files at a time.
+// All replicates must have the same players, vectors[r][i] is the value of players[i] in paths[r].
+func ShapleyReplicates(paths []string) (players []string, vectors [][]float64, err error) {
+	if len(paths) == 0 {
+		return nil, nil, errors.New("no replicate files")
+	}
+
+	all := make([][]string, len(paths))
+	vectors = make([][]float64, len(paths))
+	errs := make([]error, len(paths))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	wg.Add(len(paths))
+	for r, path := range paths {
+		go func(r int, path string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			all[r], vectors[r], errs[r] = calcFile(path)
+		}(r, path)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+	for r, ps := range all[1:] {
+		if strings.Join(ps, " ") != strings.Join(all[0], " ") {
+			return nil, nil, fmt.Errorf("%s has other players than %s", paths[r+1], paths[0])
+		}
+	}
+
+	return all[0], vectors, nil
+}
+
+// Bootstrap resamples the replicates with replacement and returns, for every player, the mean over replicates,
+// the percentile confidence interval of the mean at the given level and how often the player lands in the top k.
+func Bootstrap(vectors [][]float64, resamples, k int, level float64, rng *rand.Rand) []BootstrapStats {
+	n, reps := len(vectors[0]), len(vectors)
+	stats := make([]BootstrapStats, n)
+	for _, vector := range vectors {
+		for i, value := range vector {
+			stats[i].Mean += value / float64(reps)
+		}
+	}
+
+	means := make([][]float64, n)
+	for i := range means {
+		means[i] = make([]float64, resamples)
+	}
+	mean := make([]float64, n)
+	order := make([]int, n)
+	for b := 0; b < resamples; b++ {
+		for i := range mean {
+			mean[i] = 0
+		}
+		for r := 0; r < reps; r++ {
+			for i, value := range vectors[rng.Intn(reps)] {
+				mean[i] += value / float64(reps)
+			}
+		}
+		for i := range order {
+			order[i] = i
+			means[i][b] = mean[i]
+		}
+		sort.Slice(order, func(a, b int) bool { return mean[order[a]] > mean[order[b]] })
+		for _, i := range order[:k] {
+			stats[i].TopK++
+		}
+	}
+
+	alpha := (1 - level) / 2
+	for i := range stats {
+		sort.Float64s(means[i])
+		stats[i].Lo = quantile(means[i], alpha)
+		stats[i].Hi = quantile(means[i], 1-alpha)
+		stats[i].TopK /= float64(resamples)
+	}
+
+	return stats
+}
+
+// quantile of sorted values with linear interpolation between the closest ranks.
+func quantile(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	if lo+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+
+	return sorted[lo] + (pos-float64(lo))*(sorted[lo+1]-sorted[lo])
+}
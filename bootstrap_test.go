@@ -0,0 +1,68 @@
+package shapley
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_bootstrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		vectors [][]float64
+		want    []BootstrapStats
+	}{
+		{
+			name:    "identical",
+			vectors: [][]float64{{0.2, 0.3, 0.5}, {0.2, 0.3, 0.5}},
+			want:    []BootstrapStats{{Mean: 0.2, Lo: 0.2, Hi: 0.2}, {Mean: 0.3, Lo: 0.3, Hi: 0.3, TopK: 1}, {Mean: 0.5, Lo: 0.5, Hi: 0.5, TopK: 1}},
+		},
+		{
+			name:    "varying",
+			vectors: [][]float64{{0.1, 0.4, 0.5}, {0.3, 0.2, 0.5}, {0.2, 0.3, 0.5}},
+			want:    []BootstrapStats{{Mean: 0.2, Lo: 0.1, Hi: 0.3}, {Mean: 0.3, Lo: 0.2, Hi: 0.4}, {Mean: 0.5, Lo: 0.5, Hi: 0.5, TopK: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Bootstrap(tt.vectors, 500, 2, 0.95, rand.New(rand.NewSource(1)))
+			for i, want := range tt.want {
+				g := got[i]
+				if math.Abs(g.Mean-want.Mean) > 1e-9 || g.Lo < want.Lo-1e-9 || g.Hi > want.Hi+1e-9 || g.Lo > g.Mean || g.Hi < g.Mean {
+					t.Errorf("Bootstrap()[%d] = %+v, want mean %v within [%v, %v]", i, g, want.Mean, want.Lo, want.Hi)
+				}
+				if want.TopK == 1 && g.TopK != 1 {
+					t.Errorf("Bootstrap()[%d].topK = %v, want 1", i, g.TopK)
+				}
+			}
+		})
+	}
+}
+
+func Test_ShapleyReplicates(t *testing.T) {
+	players, vectors, err := ShapleyReplicates([]string{"data/N9", "data/N9"})
+	if err != nil {
+		t.Fatalf("ShapleyReplicates() error = %v", err)
+	}
+	if len(players) != 9 || len(vectors) != 2 {
+		t.Errorf("ShapleyReplicates() = %d players, %d vectors", len(players), len(vectors))
+	}
+	if _, _, err := ShapleyReplicates([]string{"data/N9", "data/N11"}); err == nil {
+		t.Errorf("ShapleyReplicates() error = nil, want error")
+	}
+
+	// replicates needn't be normalized, v(N) = 3 here
+	path := filepath.Join(t.TempDir(), "N2")
+	if err := os.WriteFile(path, []byte("A,1\nB,1.5\nA B,0.5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, vectors, err = ShapleyReplicates([]string{path})
+	if err != nil {
+		t.Fatalf("ShapleyReplicates() error = %v", err)
+	}
+	if got := vectors[0][0] + vectors[0][1]; math.Abs(got-3) > 1e-9 {
+		t.Errorf("ShapleyReplicates() sum = %v, want 3", got)
+	}
+}
@@ -0,0 +1,273 @@
+package shapley
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// The catalog of classic games: every constructor returns the game and its Shapley value in closed form,
+// computed without enumerating coalitions, which makes them ground truth for shapley.
+
+// NumberedPlayers returns prefix1, ..., prefixN zero-padded so that the names sort in order.
+func NumberedPlayers(prefix string, n int) []string {
+	width := len(strconv.Itoa(n))
+	players := make([]string, n)
+	for i := range players {
+		players[i] = fmt.Sprintf("%s%0*d", prefix, width, i+1)
+	}
+
+	return players
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	c := 1.0
+	for i := 1; i <= k; i++ {
+		c = c * float64(n-k+i) / float64(i)
+	}
+
+	return c
+}
+
+// pivotal returns the probability that, in a random order of own-1 players of one kind and other players
+// of the other kind, a player of the first kind arriving uniformly at random finds more of the other kind
+// than of its own before it: φ = 1/n Σ_k Σ_{b>k-b} C(other,b)·C(own-1,k-b)/C(n-1,k).
+func pivotal(own, other int) float64 {
+	n := own + other
+	var p float64
+	for k := 0; k < n; k++ {
+		for b := 0; b <= k; b++ {
+			if b > k-b {
+				p += binomial(other, b) * binomial(own-1, k-b) / binomial(n-1, k)
+			}
+		}
+	}
+
+	return p / float64(n)
+}
+
+// GloveGame has left owners of a left glove and right owners of a right glove, v(S) is the number of pairs in S.
+func GloveGame(left, right int) (*Game, map[string]float64) {
+	g := NewGame(append(NumberedPlayers("L", left), NumberedPlayers("R", right)...))
+	L := Grand(left)
+	for s := 1; s <= int(g.Grand()); s++ {
+		S := Coalition(s)
+		l, r := S.Intersect(L).Size(), S.Minus(L).Size()
+		g.Worths[S] = math.Min(float64(l), float64(r))
+	}
+
+	sValues := make(map[string]float64, left+right)
+	for i, player := range g.Players {
+		if i < left {
+			sValues[player] = pivotal(left, right)
+		} else {
+			sValues[player] = pivotal(right, left)
+		}
+	}
+
+	return g, sValues
+}
+
+// AirportGame is the cost game of a runway: v(S) = max_{i∈S} c_i.
+// Littlechild–Owen: with costs sorted ascending, φ_i = Σ_{k≤i} (c_k - c_{k-1})/(n-k+1).
+func AirportGame(costs []float64) (*Game, map[string]float64) {
+	n := len(costs)
+	g := NewGame(NumberedPlayers("P", n))
+	bitset := g.bits()
+	for s := 1; s <= int(g.Grand()); s++ {
+		S := Coalition(s)
+		var worth float64
+		for i, bs := range bitset {
+			if S&bs != 0 {
+				worth = math.Max(worth, costs[i])
+			}
+		}
+		g.Worths[S] = worth
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return costs[order[a]] < costs[order[b]] })
+
+	sValues := make(map[string]float64, n)
+	var share, prev float64
+	for k, i := range order {
+		share += (costs[i] - prev) / float64(n-k)
+		prev = costs[i]
+		sValues[g.Players[i]] = share
+	}
+
+	return g, sValues
+}
+
+// BankruptcyGame divides an estate among claims: v(S) = max(0, E - Σ_{j∉S} d_j).
+// Its Shapley value is the random arrival rule, φ_i = Σ_S |S|!(n-|S|-1)!/n! · min(d_i, max(0, E - d(S))),
+// computed from the distribution of claim sums by coalition size instead of the coalitions themselves.
+func BankruptcyGame(estate float64, claims []float64) (*Game, map[string]float64) {
+	n := len(claims)
+	g := NewGame(NumberedPlayers("P", n))
+	var total float64
+	for _, d := range claims {
+		total += d
+	}
+	bitset := g.bits()
+	for s := 1; s <= int(g.Grand()); s++ {
+		S := Coalition(s)
+		outside := total
+		for i, bs := range bitset {
+			if S&bs != 0 {
+				outside -= claims[i]
+			}
+		}
+		g.Worths[S] = math.Max(0, estate-outside)
+	}
+
+	sValues := make(map[string]float64, n)
+	for i, player := range g.Players {
+		// sums[k] counts the coalitions of k other players by their total claim.
+		sums := make([]map[float64]float64, n)
+		sums[0] = map[float64]float64{0: 1}
+		for k := 1; k < n; k++ {
+			sums[k] = make(map[float64]float64)
+		}
+		for j, d := range claims {
+			if j == i {
+				continue
+			}
+			for k := n - 2; k >= 0; k-- {
+				for sum, c := range sums[k] {
+					sums[k+1][sum+d] += c
+				}
+			}
+		}
+
+		var value float64
+		for k, byClaim := range sums {
+			// Weight = k!(n-k-1)!/n!
+			w := 1 / (float64(n) * binomial(n-1, k))
+			for sum, c := range byClaim {
+				value += w * c * math.Min(claims[i], math.Max(0, estate-sum))
+			}
+		}
+		sValues[player] = value
+	}
+
+	return g, sValues
+}
+
+// Talmud is the Aumann–Maschler division of an estate: constrained equal awards of the half-claims
+// while the estate is at most half of the claims, otherwise the claims less constrained equal losses.
+func Talmud(estate float64, claims []float64) []float64 {
+	half := make([]float64, len(claims))
+	var total float64
+	for i, d := range claims {
+		half[i] = d / 2
+		total += d
+	}
+
+	if estate <= total/2 {
+		return equalAwards(half, estate)
+	}
+	losses := equalAwards(half, total-estate)
+	for i, d := range claims {
+		losses[i] = d - losses[i]
+	}
+
+	return losses
+}
+
+// equalAwards returns x_i = min(c_i, λ) with Σ x_i = amount ≤ Σ c_i.
+func equalAwards(caps []float64, amount float64) []float64 {
+	order := make([]int, len(caps))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return caps[order[a]] < caps[order[b]] })
+
+	awards := make([]float64, len(caps))
+	left := amount
+	for k, i := range order {
+		lambda := left / float64(len(order)-k)
+		if caps[i] <= lambda {
+			awards[i] = caps[i]
+			left -= caps[i]
+			continue
+		}
+		for _, j := range order[k:] {
+			awards[j] = lambda
+		}
+		break
+	}
+
+	return awards
+}
+
+// UnanimityGame on n players with carrier T = the first t players: v(S) = 1 when T ⊆ S, φ_i = 1/t on T.
+func UnanimityGame(n, t int) (*Game, map[string]float64) {
+	g := NewGame(NumberedPlayers("P", n))
+	T := Coalition(1<<t - 1)
+	for s := 1; s <= int(g.Grand()); s++ {
+		if S := Coalition(s); S&T == T {
+			g.Worths[S] = 1
+		}
+	}
+
+	sValues := make(map[string]float64, n)
+	for i, player := range g.Players {
+		if i < t {
+			sValues[player] = 1 / float64(t)
+		} else {
+			sValues[player] = 0
+		}
+	}
+
+	return g, sValues
+}
+
+// MajorityGame on n players: v(S) = 1 when |S| ≥ quota, all players are symmetric so φ_i = 1/n.
+func MajorityGame(n, quota int) (*Game, map[string]float64) {
+	g := NewGame(NumberedPlayers("P", n))
+	for s := 1; s <= int(g.Grand()); s++ {
+		if S := Coalition(s); S.Size() >= quota {
+			g.Worths[S] = 1
+		}
+	}
+
+	sValues := make(map[string]float64, n)
+	for _, player := range g.Players {
+		sValues[player] = 1 / float64(n)
+	}
+
+	return g, sValues
+}
+
+// ApexGame has an apex player A and n-1 minor players: a coalition wins with A and a minor player or with all minor players.
+// φ_A = (n-2)/n and φ_minor = 2/(n(n-1)).
+func ApexGame(n int) (*Game, map[string]float64) {
+	g := NewGame(append([]string{"A"}, NumberedPlayers("M", n-1)...))
+	apex := g.bits()[0]
+	for s := 1; s <= int(g.Grand()); s++ {
+		S := Coalition(s)
+		minors := S &^ apex
+		if (S&apex != 0 && minors != 0) || minors == g.Grand()&^apex {
+			g.Worths[S] = 1
+		}
+	}
+
+	sValues := make(map[string]float64, n)
+	for i, player := range g.Players {
+		if i == 0 {
+			sValues[player] = float64(n-2) / float64(n)
+		} else {
+			sValues[player] = 2 / float64(n*(n-1))
+		}
+	}
+
+	return g, sValues
+}
@@ -0,0 +1,29 @@
+package shapley
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_talmud(t *testing.T) {
+	claims := []float64{100, 200, 300}
+	tests := []struct {
+		name   string
+		want   []float64
+		estate float64
+	}{
+		{name: "100", estate: 100, want: []float64{100. / 3, 100. / 3, 100. / 3}},
+		{name: "200", estate: 200, want: []float64{50, 75, 75}},
+		{name: "300", estate: 300, want: []float64{50, 100, 150}},
+		{name: "500", estate: 500, want: []float64{200. / 3, 500. / 3, 800. / 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, value := range Talmud(tt.estate, claims) {
+				if math.Abs(tt.want[i]-value) > 1e-9 {
+					t.Errorf("Talmud()[%d] = %v, want %v", i, value, tt.want[i])
+				}
+			}
+		})
+	}
+}
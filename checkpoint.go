@@ -0,0 +1,202 @@
+package shapley
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// Checkpoint is the state of an exact computation: the compensated sums of the terms of the chunks From up to Next
+// of every player and bounds of their rounding errors. The computation is done when Next reaches To.
+// A shard of a computation is a checkpoint of a part of the chunks, see Solver.ShapleyShard.
+type Checkpoint struct {
+	Players     []string  `json:"players"`
+	Sums        []float64 `json:"sums"`
+	Comps       []float64 `json:"comps"` // compensations of the Neumaier sums
+	Errs        []float64 `json:"errs"`
+	Fingerprint uint64    `json:"fingerprint"` // of the players and the worths, see fingerprint
+	Grand       float64   `json:"grand"`       // v(N)
+	WeightPrec  uint      `json:"weight_prec"`
+	ChunkBits   int       `json:"chunk_bits"`
+	From        int       `json:"from"`
+	Next        int       `json:"next"`
+	To          int       `json:"to"`
+}
+
+func newCheckpoint(players []string, prec uint, from, to int) *Checkpoint {
+	n := len(players)
+	return &Checkpoint{
+		Players:    players,
+		Sums:       make([]float64, n),
+		Comps:      make([]float64, n),
+		Errs:       make([]float64, n),
+		WeightPrec: prec,
+		ChunkBits:  chunkBits,
+		From:       from,
+		Next:       from,
+		To:         to,
+	}
+}
+
+// LoadCheckpoint reads a checkpoint file.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint, %w", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[WARN] closing file: %v", err)
+		}
+	}()
+
+	var cp Checkpoint
+	if err := json.NewDecoder(f).Decode(&cp); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s, %w", path, err)
+	}
+	if n := len(cp.Players); len(cp.Sums) != n || len(cp.Comps) != n || len(cp.Errs) != n {
+		return nil, fmt.Errorf("checkpoint %s has sums of %d, %d and %d of %d players", path, len(cp.Sums), len(cp.Comps), len(cp.Errs), n)
+	}
+	if cp.From > cp.Next || cp.Next > cp.To {
+		return nil, fmt.Errorf("checkpoint %s has chunks %d <= %d <= %d out of order", path, cp.From, cp.Next, cp.To)
+	}
+
+	return &cp, nil
+}
+
+// Save writes cp to path through a temporary file, so a crash leaves either the previous or the new checkpoint.
+func (cp *Checkpoint) Save(path string) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint, %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := json.NewEncoder(f).Encode(cp); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to encode checkpoint, %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync checkpoint, %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint, %w", err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace checkpoint, %w", err)
+	}
+
+	return nil
+}
+
+// resumes fails unless cp is a state of the computation that fresh starts, of the same game, weights and chunks.
+func (cp *Checkpoint) resumes(fresh *Checkpoint) error {
+	switch {
+	case cp.Fingerprint != fresh.Fingerprint:
+		return fmt.Errorf("checkpoint is of another game, fingerprint %x instead of %x", cp.Fingerprint, fresh.Fingerprint)
+	case cp.WeightPrec != fresh.WeightPrec:
+		return fmt.Errorf("checkpoint is of weight precision %d instead of %d", cp.WeightPrec, fresh.WeightPrec)
+	case cp.ChunkBits != fresh.ChunkBits || cp.From != fresh.From || cp.To != fresh.To:
+		return fmt.Errorf("checkpoint is of chunks %d to %d of 2^%d coalitions instead of %d to %d of 2^%d",
+			cp.From, cp.To, cp.ChunkBits, fresh.From, fresh.To, fresh.ChunkBits)
+	}
+
+	return nil
+}
+
+// done returns the number of non-empty coalitions of the chunks in the sums.
+func (cp *Checkpoint) done() int {
+	return coalitionsOf(len(cp.Players), cp.From, cp.Next)
+}
+
+// values returns the sums by player with their error bounds and the sum of the values.
+func (cp *Checkpoint) values() (sValues, bounds map[string]float64, vSum float64) {
+	sValues = make(map[string]float64, len(cp.Players))
+	bounds = make(map[string]float64, len(cp.Players))
+	for i, player := range cp.Players {
+		value := (&neumaier{sum: cp.Sums[i], c: cp.Comps[i]}).result()
+		vSum += value
+		sValues[player] = value
+		bounds[player] = cp.Errs[i] + 2*unitRoundoff*math.Abs(value)
+	}
+
+	return sValues, bounds, vSum
+}
+
+// MergeShards adds up the partial sums of the shards of a computation into its values. It fails unless the shards are
+// done and of the same game, and cover every chunk once, or when the values don't sum to v(N).
+func MergeShards(shards []*Checkpoint) (*Result, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("no shards")
+	}
+	shards = append([]*Checkpoint(nil), shards...)
+	sort.Slice(shards, func(i, j int) bool {
+		return shards[i].From < shards[j].From
+	})
+
+	first := shards[0]
+	var next int
+	for _, cp := range shards {
+		switch {
+		case cp.Fingerprint != first.Fingerprint || !reflect.DeepEqual(cp.Players, first.Players):
+			return nil, fmt.Errorf("shards of chunks %d and %d are of different games", first.From, cp.From)
+		case cp.WeightPrec != first.WeightPrec:
+			return nil, fmt.Errorf("shards of chunks %d and %d are of different weight precisions", first.From, cp.From)
+		case cp.ChunkBits != chunkBits:
+			return nil, fmt.Errorf("shard of chunk %d has chunks of 2^%d coalitions instead of 2^%d", cp.From, cp.ChunkBits, chunkBits)
+		case cp.From < next:
+			return nil, fmt.Errorf("shards overlap in chunks %d to %d", cp.From, next)
+		case cp.From > next:
+			return nil, fmt.Errorf("no shard of chunks %d to %d", next, cp.From)
+		case cp.Next < cp.To:
+			return nil, fmt.Errorf("shard of chunks %d to %d is done up to %d only", cp.From, cp.To, cp.Next)
+		}
+		next = cp.To
+	}
+	if chunks := numChunks(len(first.Players)); next != chunks {
+		return nil, fmt.Errorf("no shard of chunks %d to %d", next, chunks)
+	}
+
+	merged := newCheckpoint(first.Players, first.WeightPrec, 0, next)
+	merged.Next = next
+	for i := range merged.Sums {
+		var pSum neumaier
+		for _, cp := range shards {
+			value := (&neumaier{sum: cp.Sums[i], c: cp.Comps[i]}).result()
+			pSum.add(value)
+			merged.Errs[i] += cp.Errs[i] + 2*unitRoundoff*math.Abs(value)
+		}
+		merged.Sums[i], merged.Comps[i] = pSum.sum, pSum.c
+	}
+	values, bounds, sum := merged.values()
+	if err := checkEfficient(sum, first.Grand); err != nil {
+		return nil, err
+	}
+
+	return &Result{Values: values, Bounds: bounds, Sum: sum, Coverage: 1}, nil
+}
+
+// fingerprint is the FNV-1a hash of the players and the bits of the worths of the dense table.
+func fingerprint(players []string, table []float64) uint64 {
+	h := fnv.New64a()
+	for _, player := range players {
+		h.Write([]byte(player))
+		h.Write([]byte{0})
+	}
+	var buf [8]byte
+	for _, worth := range table {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(worth))
+		h.Write(buf[:])
+	}
+
+	return h.Sum64()
+}
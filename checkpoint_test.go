@@ -0,0 +1,107 @@
+package shapley
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Checkpoint(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	g := NewGame(NumberedPlayers("P", 14))
+	for S := Coalition(1); S <= g.Grand(); S++ {
+		g.Worths[S] = rng.Float64()
+	}
+	want, err := Shapley(g)
+	if err != nil {
+		t.Fatalf("Shapley() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupted := Solver{Checkpoint: path, Progress: func(done, total int) {
+		if done >= total/2 {
+			cancel()
+		}
+	}}
+	partial, err := interrupted.ShapleyContext(ctx, g)
+	if !errors.Is(err, context.Canceled) || partial.Coverage >= 1 {
+		t.Fatalf("ShapleyContext() coverage = %v, error = %v, want canceled", partial.Coverage, err)
+	}
+	cp, err := LoadCheckpoint(path)
+	if err != nil || cp.Next != 2 || cp.To != 4 {
+		t.Fatalf("LoadCheckpoint() = %+v, %v, want chunk 2 of 4 next", cp, err)
+	}
+
+	got, err := Solver{Checkpoint: path}.Shapley(g)
+	if err != nil {
+		t.Fatalf("Shapley() of the checkpoint error = %v", err)
+	}
+	for _, player := range g.Players {
+		if got.Values[player] != want.Values[player] || got.Bounds[player] != want.Bounds[player] {
+			t.Errorf("%s: resumed %v ± %v, want %v ± %v", player, got.Values[player], got.Bounds[player],
+				want.Values[player], want.Bounds[player])
+		}
+	}
+
+	if _, err := (Solver{Checkpoint: path, WeightPrec: 128}).Shapley(g); err == nil {
+		t.Errorf("Shapley() of the checkpoint with another weight precision error = nil")
+	}
+	g.Worths[1] += 1e-12
+	if _, err := (Solver{Checkpoint: path}).Shapley(g); err == nil {
+		t.Errorf("Shapley() of the checkpoint of another game error = nil")
+	}
+}
+
+func Test_MergeShards(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	g := NewGame(NumberedPlayers("P", 14))
+	for S := Coalition(1); S <= g.Grand(); S++ {
+		g.Worths[S] = rng.Float64()
+	}
+	want, _ := Shapley(g)
+
+	shards := make([]*Checkpoint, 3)
+	for i := range shards {
+		var err error
+		if shards[i], err = (Solver{}).ShapleyShard(context.Background(), g, i, len(shards)); err != nil {
+			t.Fatalf("ShapleyShard(%d) error = %v", i, err)
+		}
+	}
+	got, err := MergeShards([]*Checkpoint{shards[2], shards[0], shards[1]})
+	if err != nil {
+		t.Fatalf("MergeShards() error = %v", err)
+	}
+	for _, player := range g.Players {
+		if diff := math.Abs(got.Values[player] - want.Values[player]); diff > got.Bounds[player]+want.Bounds[player] {
+			t.Errorf("%s: merged %v, want %v", player, got.Values[player], want.Values[player])
+		}
+	}
+
+	other := NewGame(g.Players)
+	for S, worth := range g.Worths {
+		other.Worths[S] = 2 * worth
+	}
+	foreign, _ := (Solver{}).ShapleyShard(context.Background(), other, 1, len(shards))
+	undone := *shards[1]
+	undone.Next = undone.From
+	for name, bad := range map[string][]*Checkpoint{
+		"missing":   {shards[0], shards[2]},
+		"overlap":   {shards[0], shards[1], shards[1], shards[2]},
+		"foreign":   {shards[0], foreign, shards[2]},
+		"undone":    {shards[0], &undone, shards[2]},
+		"too many":  {shards[0], shards[1], shards[2], foreign},
+		"no shards": nil,
+	} {
+		if _, err := MergeShards(bad); err == nil {
+			t.Errorf("MergeShards() of %s shards error = nil", name)
+		}
+	}
+	if _, err := (Solver{}).ShapleyShard(context.Background(), g, 0, 5); err == nil {
+		t.Errorf("ShapleyShard() of 5 shards of 4 chunks error = nil")
+	}
+}
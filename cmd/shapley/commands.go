@@ -0,0 +1,752 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/razor-87/shapley"
+)
+
+var errNoFile = errors.New("flag -file is required")
+
+// commands maps a subcommand name (the first positional argument) to its entry point.
+// Without a subcommand the binary computes the Shapley values of data/N<genes>.
+var commands = map[string]func(args []string) error{
+	"voting":     runVoting,
+	"transform":  runTransform,
+	"properties": runProperties,
+	"generate":   runGenerate,
+	"bootstrap":  runBootstrap,
+	"permtest":   runPermutationTest,
+	"gsea":       runGSEA,
+	"meta":       runMeta,
+	"external":   runExternal,
+	"shard":      runShard,
+	"merge":      runMerge,
+}
+
+// dataFile returns file, or else the global -file, or else data/N<genes>.
+func dataFile(file string) string {
+	switch {
+	case file != "":
+		return file
+	case *input != "":
+		return *input
+	default:
+		return "data/N" + strconv.Itoa(*genes)
+	}
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// writeFile creates path and fills it with write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create game file, %w", err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write game, %w", err)
+	}
+
+	return f.Close()
+}
+
+func parseFloats(s string) ([]float64, error) {
+	items := splitList(s)
+	nums := make([]float64, len(items))
+	for i, item := range items {
+		num, err := strconv.ParseFloat(item, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert string to float, %w", err)
+		}
+		nums[i] = num
+	}
+
+	return nums, nil
+}
+
+func runVoting(args []string) error {
+	fs := flag.NewFlagSet("voting", flag.ContinueOnError)
+	file := fs.String("file", "", "voting game specification")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errNoFile
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("failed to open voting file, %w", err)
+	}
+	defer f.Close()
+
+	vg, err := shapley.ParseVoting(f)
+	if err != nil {
+		return fmt.Errorf("failed to parse voting game, %w", err)
+	}
+	ss, bz, err := shapley.VotingPower(vg)
+	if err != nil {
+		return fmt.Errorf("failed to compute voting power, %w", err)
+	}
+
+	printValues(os.Stdout, "Player", "Shapley-Shubik index", ss)
+	printValues(os.Stdout, "Player", "Banzhaf index", bz)
+
+	return nil
+}
+
+func runTransform(args []string) error {
+	fs := flag.NewFlagSet("transform", flag.ContinueOnError)
+	op := fs.String("op", "dual", "dual, normalize, strategic, sum, restrict, marginal or reduced")
+	files := fs.String("files", "", "comma-separated data files, only sum uses more than one")
+	coefs := fs.String("coefs", "", "comma-separated coefficients of sum, 1 each by default")
+	a := fs.Float64("a", 1, "scale of strategic")
+	b := fs.String("b", "", "comma-separated per-player shifts of strategic, 0 each by default")
+	players := fs.String("players", "", "comma-separated players kept by restrict and reduced, or the player of marginal")
+	out := fs.String("out", "", "write the transformed game to this file in data/N format")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := splitList(*files)
+	if len(paths) == 0 {
+		return errors.New("flag -files is required")
+	}
+
+	games := make([]*shapley.Game, len(paths))
+	for i, path := range paths {
+		g, err := shapley.LoadGame(path)
+		if err != nil {
+			return err
+		}
+		games[i] = g
+	}
+
+	var (
+		res *shapley.Game
+		err error
+	)
+	switch g := games[0]; *op {
+	case "dual":
+		res = shapley.Dual(g)
+	case "normalize":
+		res = shapley.ZeroNormalize(g)
+	case "strategic":
+		var shifts []float64
+		if shifts, err = parseFloats(*b); err == nil {
+			if len(shifts) == 0 {
+				shifts = make([]float64, len(g.Players))
+			}
+			if len(shifts) != len(g.Players) {
+				return fmt.Errorf("%d shifts for %d players", len(shifts), len(g.Players))
+			}
+			res = shapley.Strategic(g, *a, shifts)
+		}
+	case "sum":
+		var cs []float64
+		if cs, err = parseFloats(*coefs); err == nil {
+			if len(cs) == 0 {
+				cs = make([]float64, len(games))
+				for i := range cs {
+					cs[i] = 1
+				}
+			}
+			if res, err = shapley.Combine(cs, games...); err == nil {
+				err = printLinearity(res, cs, games)
+			}
+		}
+	case "restrict":
+		res, err = shapley.Restrict(g, splitList(*players))
+	case "marginal":
+		res, err = shapley.Marginal(g, *players)
+	case "reduced":
+		res, err = shapley.Reduced(g, splitList(*players))
+	default:
+		return fmt.Errorf("unknown transformation %q", *op)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to transform game, %w", err)
+	}
+
+	if *out != "" {
+		if err := writeFile(*out, func(w io.Writer) error { return shapley.WriteGame(w, res) }); err != nil {
+			return err
+		}
+	}
+
+	sValues, err := shapleyValues(res)
+	if err != nil {
+		return err
+	}
+	printValues(os.Stdout, "Gene", "Shapley value", sValues)
+
+	return nil
+}
+
+// printLinearity reports the largest gap between the Shapley value of a combination and the combination of the Shapley values.
+func printLinearity(sum *shapley.Game, coefs []float64, games []*shapley.Game) error {
+	want := make(map[string]float64, len(sum.Players))
+	for k, g := range games {
+		sValues, err := shapleyValues(g)
+		if err != nil {
+			return err
+		}
+		for player, value := range sValues {
+			want[player] += coefs[k] * value
+		}
+	}
+
+	got, err := shapleyValues(sum)
+	if err != nil {
+		return err
+	}
+	var gap float64
+	for player, value := range got {
+		gap = math.Max(gap, math.Abs(value-want[player]))
+	}
+	fmt.Printf("Linearity deviation: %g\n", gap)
+
+	return nil
+}
+
+// shapleyValues returns the Shapley values of g with the solver of the global flags.
+func shapleyValues(g *shapley.Game) (map[string]float64, error) {
+	res, err := solver().Shapley(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Values, nil
+}
+
+func runProperties(args []string) error {
+	fs := flag.NewFlagSet("properties", flag.ContinueOnError)
+	file := fs.String("file", "", "data file, data/N<genes> by default")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	g, err := shapley.LoadGame(dataFile(*file))
+	if err != nil {
+		return err
+	}
+	printProperties(os.Stdout, g, shapley.Analyze(g))
+
+	return nil
+}
+
+func runGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	name := fs.String("game", "", "classic game: glove, airport, bankruptcy, unanimity, majority or apex; "+
+		"game family: dirichlet, sparse, kadditive or noisy")
+	n := fs.Int("n", 5, "number of players of unanimity, majority, apex and the game families")
+	params := fs.String("params", "", "comma-separated parameters: glove left,right; airport costs; "+
+		"bankruptcy estate,claims; unanimity carrier size; majority quota")
+	seed := fs.Int64("seed", 1, "random seed of the game families")
+	var fp shapley.FamilyParams
+	fs.Float64Var(&fp.Alpha, "alpha", 1, "Dirichlet concentration of the game families")
+	fs.Float64Var(&fp.Density, "density", 0.1, "share of non-zero interaction dividends of sparse")
+	fs.Float64Var(&fp.Noise, "noise", 0.01, "standard deviation of the interactions of noisy")
+	fs.IntVar(&fp.K, "k", 2, "largest coalition with a dividend of kadditive")
+	out := fs.String("out", "", "data file to write")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("flag -out is required")
+	}
+
+	switch *name {
+	case "dirichlet", "sparse", "kadditive", "noisy":
+		if *n < 1 || *n > 30 {
+			return fmt.Errorf("number of players must be between 1 and 30, %d", *n)
+		}
+		if fp.Alpha <= 0 {
+			return fmt.Errorf("alpha must be positive, %g", fp.Alpha)
+		}
+		d, err := shapley.GenerateFamily(*name, *n, fp, rand.New(rand.NewSource(*seed)))
+		if err != nil {
+			return err
+		}
+		return writeFile(*out, func(w io.Writer) error { return shapley.WriteDividends(w, shapley.GenePlayers(*n), d) })
+	}
+
+	if *n < 2 || *n > shapley.MaxPlayers {
+		return fmt.Errorf("number of players must be between 2 and %d, %d", shapley.MaxPlayers, *n)
+	}
+	nums, err := parseFloats(*params)
+	if err != nil {
+		return err
+	}
+	// param is the integer parameter i of the game, def when it isn't given.
+	param := func(i, def int) (int, error) {
+		if i >= len(nums) {
+			return def, nil
+		}
+		if nums[i] != math.Trunc(nums[i]) {
+			return 0, fmt.Errorf("parameter %d must be an integer, %g", i+1, nums[i])
+		}
+		return int(nums[i]), nil
+	}
+
+	var (
+		g       *shapley.Game
+		sValues map[string]float64
+		talmudV []float64
+	)
+	switch *name {
+	case "glove":
+		left, err := param(0, 1)
+		if err != nil {
+			return err
+		}
+		right, err := param(1, 2)
+		if err != nil {
+			return err
+		}
+		if left < 1 || right < 1 || left+right > shapley.MaxPlayers {
+			return fmt.Errorf("glove owners must be at least 1 each and at most %d in total, %d,%d", shapley.MaxPlayers, left, right)
+		}
+		g, sValues = shapley.GloveGame(left, right)
+	case "airport":
+		if l := len(nums); l == 0 || l > shapley.MaxPlayers {
+			return fmt.Errorf("number of costs must be between 1 and %d, %d", shapley.MaxPlayers, l)
+		}
+		g, sValues = shapley.AirportGame(nums)
+	case "bankruptcy":
+		if len(nums) < 2 {
+			return errors.New("bankruptcy needs an estate and claims")
+		}
+		if l := len(nums) - 1; l > shapley.MaxPlayers {
+			return fmt.Errorf("number of claims must be between 1 and %d, %d", shapley.MaxPlayers, l)
+		}
+		g, sValues = shapley.BankruptcyGame(nums[0], nums[1:])
+		talmudV = shapley.Talmud(nums[0], nums[1:])
+	case "unanimity":
+		t, err := param(0, *n)
+		if err != nil {
+			return err
+		}
+		if t < 1 || t > *n {
+			return fmt.Errorf("carrier size must be between 1 and %d, %d", *n, t)
+		}
+		g, sValues = shapley.UnanimityGame(*n, t)
+	case "majority":
+		quota, err := param(0, *n/2+1)
+		if err != nil {
+			return err
+		}
+		if quota < 1 || quota > *n {
+			return fmt.Errorf("quota must be between 1 and %d, %d", *n, quota)
+		}
+		g, sValues = shapley.MajorityGame(*n, quota)
+	case "apex":
+		g, sValues = shapley.ApexGame(*n)
+	default:
+		return fmt.Errorf("unknown game %q", *name)
+	}
+
+	if err := writeFile(*out, func(w io.Writer) error { return shapley.WriteGame(w, g) }); err != nil {
+		return err
+	}
+
+	printValues(os.Stdout, "Player", "Shapley value", sValues)
+	if talmudV != nil {
+		tValues := make(map[string]float64, len(talmudV))
+		for i, value := range talmudV {
+			tValues[g.Players[i]] = value
+		}
+		printValues(os.Stdout, "Player", "Talmud rule", tValues)
+	}
+
+	return nil
+}
+
+func runBootstrap(args []string) error {
+	fs := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
+	files := fs.String("files", "", "comma-separated replicate data files of the same game")
+	resamples := fs.Int("resamples", 1000, "number of bootstrap resamples")
+	k := fs.Int("k", 3, "size of the top ranks for rank stability")
+	level := fs.Float64("level", 0.95, "confidence level")
+	seed := fs.Int64("seed", 1, "random seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *resamples < 1 || *level <= 0 || *level >= 1 {
+		return fmt.Errorf("invalid resamples %d or level %g", *resamples, *level)
+	}
+
+	players, vectors, err := shapley.ShapleyReplicates(splitList(*files))
+	if err != nil {
+		return err
+	}
+	if *k < 1 || *k > len(players) {
+		return fmt.Errorf("k must be between 1 and %d, %d", len(players), *k)
+	}
+
+	stats := shapley.Bootstrap(vectors, *resamples, *k, *level, rand.New(rand.NewSource(*seed)))
+	printBootstrap(os.Stdout, players, stats, *level, *k)
+
+	return nil
+}
+
+func runPermutationTest(args []string) error {
+	fs := flag.NewFlagSet("permtest", flag.ContinueOnError)
+	file := fs.String("file", "", "data file, defaults to the global -file")
+	mode := fs.String("mode", "values", "null model: values (shuffle dividends) or labels (permute players within coalition sizes)")
+	perms := fs.Int("perms", 1000, "number of permutations")
+	seed := fs.Int64("seed", 1, "random seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *perms < 1 {
+		return fmt.Errorf("invalid number of permutations %d", *perms)
+	}
+
+	g, err := shapley.LoadGame(dataFile(*file))
+	if err != nil {
+		return err
+	}
+	results, err := shapley.PermutationTest(g, *mode, *perms, rand.New(rand.NewSource(*seed)))
+	if err != nil {
+		return err
+	}
+	printPermutationTest(os.Stdout, g.Players, results)
+
+	return nil
+}
+
+func runGSEA(args []string) error {
+	fs := flag.NewFlagSet("gsea", flag.ContinueOnError)
+	file := fs.String("file", "", "data file, defaults to the global -file")
+	gmt := fs.String("gmt", "", "GMT file of gene sets")
+	perms := fs.Int("perms", 1000, "number of gene set permutations")
+	weight := fs.Float64("weight", 1, "exponent of the Shapley values in the running sum")
+	seed := fs.Int64("seed", 1, "random seed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *gmt == "" {
+		return errors.New("flag -gmt is required")
+	}
+	if *perms < 1 {
+		return fmt.Errorf("invalid number of permutations %d", *perms)
+	}
+
+	f, err := os.Open(*gmt)
+	if err != nil {
+		return fmt.Errorf("failed to open GMT file, %w", err)
+	}
+	defer f.Close()
+	sets, err := shapley.ParseGMT(f)
+	if err != nil {
+		return fmt.Errorf("failed to read GMT file, %w", err)
+	}
+
+	g, err := shapley.LoadGame(dataFile(*file))
+	if err != nil {
+		return err
+	}
+	res, err := solver().Shapley(g)
+	if err != nil {
+		return err
+	}
+	if err := res.CheckEfficient(g); err != nil {
+		return err
+	}
+
+	results := shapley.GSEA(res.Values, sets, *perms, *weight, rand.New(rand.NewSource(*seed)))
+	printEnrichment(os.Stdout, results)
+
+	return nil
+}
+
+func runMeta(args []string) error {
+	fs := flag.NewFlagSet("meta", flag.ContinueOnError)
+	files := fs.String("files", "", "comma-separated data files, one per cohort")
+	sizes := fs.String("sizes", "", "comma-separated sample sizes of the cohorts for -method size")
+	method := fs.String("method", "ivw", "pooling weights: ivw (inverse variance, needs standard error columns) or size")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	paths := splitList(*files)
+	if len(paths) == 0 {
+		return errors.New("flag -files is required")
+	}
+	ns, err := parseFloats(*sizes)
+	if err != nil {
+		return err
+	}
+
+	cohorts := make([]shapley.Cohort, len(paths))
+	for i, path := range paths {
+		if cohorts[i], err = shapley.LoadCohort(path); err != nil {
+			return err
+		}
+	}
+	results, err := shapley.MetaAnalysis(cohorts, ns, *method)
+	if err != nil {
+		return err
+	}
+	printMeta(os.Stdout, results)
+
+	return nil
+}
+
+func runExternal(args []string) error {
+	fs := flag.NewFlagSet("external", flag.ContinueOnError)
+	players := fs.String("players", "", "comma-separated players, the features of the masks in this order")
+	background := fs.String("background", "", "reference to the background dataset passed to the model with every batch")
+	batch := fs.Int("batch", 256, "coalitions per request")
+	retries := fs.Int("retries", 2, "restarts of the model process per failed batch")
+	perms := fs.Int("perms", 0, "estimate the values from this many random permutations instead of every coalition, "+
+		"n+1 evaluations each")
+	seed := fs.Int64("seed", 1, "random seed of -perms")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	names := splitList(*players)
+	if len(names) == 0 {
+		return errors.New("flag -players is required")
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no model command, pass it after the flags")
+	}
+
+	e := &shapley.External{Command: fs.Args(), Background: *background, BatchSize: *batch, Retries: *retries}
+	defer e.Close()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *perms > 0 {
+		sample, err := shapley.SampleShapley(ctx, len(names), e.BigValue(len(names)), *perms, rand.New(rand.NewSource(*seed)))
+		if err != nil {
+			return err
+		}
+		res := sample.Result(names)
+		for _, name := range ascending(res.Values) {
+			fmt.Printf("Gene: %s, Shapley value: %f, standard error: %f\n", name, res.Values[name], res.Bounds[name])
+		}
+		return nil
+	}
+
+	g, err := e.Game(ctx, names)
+	if err != nil {
+		return err
+	}
+	res, err := solver().Shapley(g)
+	if err != nil {
+		return err
+	}
+	if err := res.CheckEfficient(g); err != nil {
+		return err
+	}
+	printValues(os.Stdout, "Gene", "Shapley value", res.Values)
+
+	return nil
+}
+
+// runShard computes a shard of the coalitions into a file that is saved periodically and resumed when it exists.
+func runShard(args []string) error {
+	fs := flag.NewFlagSet("shard", flag.ContinueOnError)
+	file := fs.String("file", "", "data file, defaults to the global -file")
+	index := fs.Int("index", 0, "shard to compute, from 0")
+	count := fs.Int("of", 1, "number of shards")
+	out := fs.String("out", "", "shard file to write, resumed when it exists")
+	every := fs.Duration("checkpointevery", time.Minute, "interval between saves of the shard file")
+	table := fs.String("table", "", "memory-map the worths in this table file, rebuilt from the data file when missing or stale")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return errors.New("flag -out is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	s := solver()
+	s.Checkpoint, s.CheckpointEvery = *out, *every
+	cp, err := computeShard(ctx, s, *table, dataFile(*file), *index, *count)
+	if cp != nil && err != nil {
+		return fmt.Errorf("interrupted at chunk %d of %d to %d, rerun to resume, %w", cp.Next, cp.From, cp.To, err)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("[INFO] shard %d of %d, chunks %d to %d, written to %s", *index, *count, cp.From, cp.To, *out)
+
+	return nil
+}
+
+// computeShard computes a shard of the game of the data file, or of the table file when it is set.
+func computeShard(ctx context.Context, s shapley.Solver, table, data string, index, count int) (*shapley.Checkpoint, error) {
+	if table == "" {
+		g, err := shapley.LoadGame(data)
+		if err != nil {
+			return nil, err
+		}
+		return s.ShapleyShard(ctx, g, index, count)
+	}
+
+	t, err := openTable(table, data)
+	if err != nil {
+		return nil, err
+	}
+	defer closeTable(t)
+
+	return s.ShapleyTableShard(ctx, t, index, count)
+}
+
+// runMerge prints the Shapley values of the shard files of a computation.
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	bounds := fs.Bool("errors", false, "print an estimated rounding-error bound of every value")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no shard files, pass them after the flags")
+	}
+
+	shards := make([]*shapley.Checkpoint, fs.NArg())
+	for i, path := range fs.Args() {
+		var err error
+		if shards[i], err = shapley.LoadCheckpoint(path); err != nil {
+			return err
+		}
+	}
+	res, err := shapley.MergeShards(shards)
+	if err != nil {
+		return err
+	}
+	if *bounds {
+		printBounds(os.Stdout, res.Values, res.Bounds)
+	} else {
+		printValues(os.Stdout, "Gene", "Shapley value", res.Values)
+	}
+
+	return nil
+}
+
+// printProperties writes whether every property holds, with the worths of a counterexample when it doesn't.
+func printProperties(w io.Writer, g *shapley.Game, p *shapley.Properties) {
+	v := func(S shapley.Coalition) string {
+		if S == g.Grand() {
+			return fmt.Sprintf("v(N) = %g", g.Worths[S])
+		}
+		return fmt.Sprintf("v(%s) = %g", g.Name(S), g.Worths[S])
+	}
+
+	if c := p.Monotone; c != nil {
+		fmt.Fprintf(w, "Monotone: no, %s > %s\n", v(c.A), v(c.B))
+	} else {
+		fmt.Fprintln(w, "Monotone: yes")
+	}
+	if c := p.Superadditive; c != nil {
+		fmt.Fprintf(w, "Superadditive: no, %s < %s + %s\n", v(c.A|c.B), v(c.A), v(c.B))
+	} else {
+		fmt.Fprintln(w, "Superadditive: yes")
+	}
+	if c := p.Convex; c != nil {
+		fmt.Fprintf(w, "Convex: no, %s + %s < %s + %s\n", v(c.A|c.B), v(c.A&c.B), v(c.A), v(c.B))
+	} else {
+		fmt.Fprintln(w, "Convex: yes")
+	}
+	if p.Essential(g) {
+		fmt.Fprintf(w, "Essential: yes, %s > Σ v({i}) = %g\n", v(g.Grand()), p.Singletons)
+	} else {
+		fmt.Fprintf(w, "Essential: no, %s <= Σ v({i}) = %g\n", v(g.Grand()), p.Singletons)
+	}
+
+	pairs := make([]string, len(p.Symmetric))
+	for k, pair := range p.Symmetric {
+		pairs[k] = g.Players[pair[0]] + " ~ " + g.Players[pair[1]]
+	}
+	if len(pairs) == 0 {
+		pairs = append(pairs, "none")
+	}
+	fmt.Fprintf(w, "Symmetric pairs: %s\n", strings.Join(pairs, ", "))
+	fmt.Fprintf(w, "Null players: %s\n", playerNames(g, p.Null))
+	fmt.Fprintf(w, "Dummy players: %s\n", playerNames(g, p.Dummy))
+}
+
+func playerNames(g *shapley.Game, idx []int) string {
+	if len(idx) == 0 {
+		return "none"
+	}
+	names := make([]string, len(idx))
+	for k, i := range idx {
+		names[k] = g.Players[i]
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func printBootstrap(w io.Writer, players []string, stats []shapley.BootstrapStats, level float64, k int) {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return stats[order[a]].Mean < stats[order[b]].Mean })
+	for _, i := range order {
+		s := stats[i]
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, %g%% CI: [%f, %f], top-%d: %.3f\n",
+			players[i], s.Mean, 100*level, s.Lo, s.Hi, k, s.TopK)
+	}
+}
+
+func printPermutationTest(w io.Writer, players []string, results []shapley.PermResult) {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return results[order[a]].Value < results[order[b]].Value })
+	for _, i := range order {
+		r := results[i]
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, p-value: %.4f, q-value: %.4f\n", players[i], r.Value, r.PValue, r.QValue)
+	}
+}
+
+func printEnrichment(w io.Writer, results []shapley.Enrichment) {
+	for _, r := range results {
+		fmt.Fprintf(w, "Gene set: %s, size: %d, ES: %.4f, NES: %.4f, p-value: %.4f, FDR: %.4f\n",
+			r.Name, r.Size, r.ES, r.NES, r.PValue, r.FDR)
+	}
+}
+
+// printMeta writes one row per gene in ascending order of the pooled values.
+func printMeta(w io.Writer, results map[string]shapley.MetaResult) {
+	genes := make([]string, 0, len(results))
+	for gene := range results {
+		genes = append(genes, gene)
+	}
+	sort.Slice(genes, func(i, j int) bool { return results[genes[i]].Pooled < results[genes[j]].Pooled })
+	for _, gene := range genes {
+		r := results[gene]
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, standard error: %f, cohorts: %d, Q: %.4f, I2: %.1f%%, Borda: %.4f, RRA: %.4g\n",
+			gene, r.Pooled, r.Stderr, r.Cohorts, r.Q, 100*r.I2, r.Borda, r.RRA)
+	}
+}
@@ -0,0 +1,411 @@
+// Command shapley computes the Shapley values of the genes of a data file and runs the analyses of package shapley.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/razor-87/shapley"
+)
+
+var (
+	cpuprofile   = flag.Bool("cpuprofile", false, "write cpu profile to cpu.prof")
+	memprofile   = flag.Bool("memprofile", false, "write memory profile to mem.prof")
+	blockprofile = flag.Bool("blockprofile", false, "write block profile to block.prof")
+	tracing      = flag.Bool("trace", false, "write tracing the execution of a program to trace.out")
+	genes        = flag.Int("genes", 9, "number of genes")
+	input        = flag.String("file", "", "data file, data/N<genes> by default")
+	weightPrec   = flag.Uint("weightprec", 0, "compute Shapley weights with math/big at this precision instead of in log space")
+	errBounds    = flag.Bool("errors", false, "print an estimated rounding-error bound of every value")
+	exact        = flag.Bool("exact", false, "compute in exact rational arithmetic and print the fractions")
+	intervals    = flag.Bool("interval", false, "compute guaranteed intervals, a third column of a row is the radius of its uncertainty")
+	stderrColumn = flag.Bool("stderr", false, "propagate standard errors, the third column of a row is the standard error of its value")
+	covFile      = flag.String("cov", "", "propagate row covariances from <players>,<players>,<covariance> rows, no variances with -stderr")
+	verify       = flag.Bool("verify", false, "check symmetry, null-player and additivity of the computed values")
+	checkpoint   = flag.String("checkpoint", "", "save the state of the computation to this file and resume from it when it exists")
+	cpEvery      = flag.Duration("checkpointevery", time.Minute, "interval between saves of the -checkpoint file")
+	algoName     = flag.String("algo", "auto", "algorithm: dense, dividend, sampling or auto, dense when it fits in -memlimit")
+	memLimit     = flag.Int("memlimit", 4096, "memory limit of the algorithm in MiB")
+	perms        = flag.Int("perms", 1000, "permutations of -algo sampling, whose -errors are standard errors")
+	workers      = flag.Int("workers", 0, "number of goroutines of the computation, GOMAXPROCS by default")
+	tableFile    = flag.String("table", "", "memory-map the worths in this table file, rebuilt from the data file when missing or stale")
+	progress     = flag.Duration("progress", 10*time.Second, "report the progress and the ETA to stderr at this interval, 0 turns it off")
+)
+
+func main() {
+	flag.Parse()
+	if name := flag.Arg(0); name != "" {
+		cmd, ok := commands[name]
+		if !ok {
+			log.Fatalf("[ERROR] unknown command %q", name)
+		}
+		if err := cmd(flag.Args()[1:]); err != nil {
+			log.Fatalf("[ERROR] %v", err)
+		}
+		return
+	}
+
+	r, err := mode()
+	if err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+	if err := r(); err != nil {
+		log.Fatalf("[ERROR] %v", err)
+	}
+}
+
+// mode returns the entry point selected by the global flags. The modes read the data differently,
+// -interval and -stderr both take the third column of a row, so at most one of them may be set.
+// Profiling, -errors/-verify, -checkpoint and -table apply to the default run only.
+func mode() (func() error, error) {
+	r := run
+	var modes []string
+	if *exact {
+		r = runExact
+		modes = append(modes, "-exact")
+	}
+	if *intervals {
+		r = runInterval
+		modes = append(modes, "-interval")
+	}
+	if *stderrColumn || *covFile != "" {
+		r = runUncertainty
+		modes = append(modes, "-stderr/-cov")
+	}
+	if len(modes) > 1 {
+		return nil, fmt.Errorf("conflicting flags %s", strings.Join(modes, ", "))
+	}
+
+	profiling := *cpuprofile || *memprofile || *blockprofile || *tracing
+	if len(modes) == 1 {
+		switch {
+		case profiling:
+			return nil, fmt.Errorf("profiling flags can't be used with %s", modes[0])
+		case *errBounds || *verify:
+			return nil, fmt.Errorf("flags -errors and -verify can't be used with %s", modes[0])
+		case *checkpoint != "" || *tableFile != "":
+			return nil, fmt.Errorf("flags -checkpoint and -table can't be used with %s", modes[0])
+		}
+	}
+	if *tableFile != "" && *verify {
+		return nil, errors.New("flag -verify can't be used with -table")
+	}
+	if profiling {
+		r = runWithFlags
+	}
+
+	return r, nil
+}
+
+// run prints the Shapley values of the data file. On Ctrl-C the computation stops and the partial sums
+// of the coalitions processed so far are printed with their coverage before the error.
+func run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	res, err := calc(ctx)
+	if res == nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	switch {
+	case err != nil:
+		// the sums over the coalitions of the lowest bitmasks, not estimates of the values
+		fmt.Printf("Partial sums over %.1f%% of the coalitions, not Shapley values:\n", 100*res.Coverage)
+		printValues(os.Stdout, "Gene", "partial sum", res.Values)
+	case *errBounds:
+		printBounds(os.Stdout, res.Values, res.Bounds)
+	default:
+		printValues(os.Stdout, "Gene", "Shapley value", res.Values)
+	}
+	fmt.Printf("Measure time: %s\n", elapsed)
+	if err != nil && *checkpoint != "" {
+		return fmt.Errorf("interrupted after %.1f%% of the coalitions, rerun with -checkpoint %s to resume, %w",
+			100*res.Coverage, *checkpoint, err)
+	}
+	if err != nil {
+		return fmt.Errorf("interrupted after %.1f%% of the coalitions, %w", 100*res.Coverage, err)
+	}
+
+	return nil
+}
+
+// printValues writes values in ascending order, one "<player>: <name>, <index>: <value>" line each.
+func printValues(w io.Writer, player, index string, values map[string]float64) {
+	for _, name := range ascending(values) {
+		fmt.Fprintf(w, "%s: %s, %s: %f\n", player, name, index, values[name])
+	}
+}
+
+// printBounds is printValues of the Shapley values with their estimated rounding errors.
+func printBounds(w io.Writer, values, bounds map[string]float64) {
+	for _, name := range ascending(values) {
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, error bound: %.1e\n", name, values[name], bounds[name])
+	}
+}
+
+// ascending returns the names of values in ascending order of their values.
+func ascending(values map[string]float64) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return values[names[i]] < values[names[j]]
+	})
+
+	return names
+}
+
+func runWithFlags() error {
+	if *cpuprofile {
+		f, err := os.Create("cpu.prof")
+		if err != nil {
+			return fmt.Errorf("could not create CPU profile: %w", err)
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return fmt.Errorf("could not start CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+	if *blockprofile {
+		runtime.SetBlockProfileRate(1)
+		f, err := os.Create("block.prof")
+		if err != nil {
+			return fmt.Errorf("could not create block profile: %w", err)
+		}
+		defer f.Close()
+		defer func() {
+			if err = pprof.Lookup("block").WriteTo(f, 0); err != nil {
+				log.Printf("[WARN] write to file: %v", err)
+			}
+		}()
+	}
+	if *tracing {
+		f, err := os.Create("trace.out")
+		if err != nil {
+			return fmt.Errorf("failed to create trace output file: %w", err)
+		}
+		defer f.Close()
+		if err := trace.Start(f); err != nil {
+			return fmt.Errorf("failed to start trace: %w", err)
+		}
+		defer trace.Stop()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if _, err := calc(ctx); err != nil {
+		return err
+	}
+
+	if *memprofile {
+		f, err := os.Create("mem.prof")
+		if err != nil {
+			return fmt.Errorf("could not create memory profile: %w", err)
+		}
+		defer f.Close()
+		runtime.GC() // get up-to-date statistics
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return fmt.Errorf("could not write memory profile: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// calc returns the Shapley values of the data file, or the partial result and the error of ctx when it is done first.
+func calc(ctx context.Context) (*shapley.Result, error) {
+	if *tableFile != "" {
+		return calcTable(ctx)
+	}
+	records, err := readRecords()
+	if err != nil {
+		return nil, err
+	}
+	algo, err := chooseAlgorithm(records)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *shapley.Result
+	switch algo {
+	case shapley.Dividend:
+		res, err = solver().ShapleyDividends(records)
+	case shapley.Sampling:
+		res, err = solver().ShapleySampling(ctx, records, *perms, rand.New(rand.NewSource(1)))
+	default:
+		return calcDense(ctx, records)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// chooseAlgorithm returns the algorithm of -algo, or the one shapley.ChooseAlgorithm picks within -memlimit, and
+// refuses an algorithm that doesn't fit before it allocates anything. The flags of denseFlags select the dense
+// algorithm under auto and are refused with another one.
+func chooseAlgorithm(records [][]string) (shapley.Algorithm, error) {
+	algo := shapley.Algorithm(*algoName)
+	if set := denseFlags(); len(set) > 0 {
+		if algo != "auto" && algo != shapley.Dense {
+			return "", fmt.Errorf("only -algo dense applies %s, not %s", strings.Join(set, ", "), algo)
+		}
+		algo = shapley.Dense
+	}
+
+	est, err := shapley.ChooseAlgorithm(shapley.EstimateAlgorithms(records, *perms, *workers), algo, float64(*memLimit)*(1<<20))
+	switch {
+	case err != nil && est.Algorithm == shapley.Dense && est.Err == nil:
+		return "", fmt.Errorf("%w, use -algo dividend, -table or a larger -memlimit", err)
+	case err != nil && est.Algorithm == shapley.Dense:
+		return "", fmt.Errorf("%w, use -algo dividend or -table", err)
+	case err != nil:
+		return "", err
+	}
+	if *progress > 0 && est.Time >= *progress {
+		log.Printf("[INFO] %s algorithm, about %s of memory and %s", est.Algorithm, shapley.FormatBytes(est.Memory), est.Time.Round(time.Second))
+	}
+
+	return est.Algorithm, nil
+}
+
+// denseFlags returns the flags set on the command line that only the dense algorithm applies.
+func denseFlags() []string {
+	var set []string
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "verify", "checkpoint", "checkpointevery", "weightprec", "workers", "progress":
+			set = append(set, "-"+f.Name)
+		}
+	})
+
+	return set
+}
+
+// calcDense is calc of the dense algorithm, which computes the worths of the game of records.
+func calcDense(ctx context.Context, records [][]string) (*shapley.Result, error) {
+	g, err := shapley.ParseGame(records)
+	if err != nil {
+		return nil, err
+	}
+
+	s := solver()
+	s.Checkpoint, s.CheckpointEvery = *checkpoint, *cpEvery
+	res, err := s.ShapleyContext(ctx, g)
+	if err != nil {
+		return res, err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return nil, err
+	}
+	if *verify {
+		if violations := shapley.VerifyAxioms(g, res.Values, rand.New(rand.NewSource(1))); len(violations) > 0 {
+			for _, v := range violations {
+				log.Printf("[WARN] %s axiom violated for %s by %g", v.Axiom, v.Players, v.Gap)
+			}
+			return nil, fmt.Errorf("Shapley values violate %d axiom checks", len(violations))
+		}
+	}
+
+	return res, nil
+}
+
+// calcTable is calc over the worths of the -table file, which is built from the data file first, see openTable.
+func calcTable(ctx context.Context) (*shapley.Result, error) {
+	t, err := openTable(*tableFile, dataFile(""))
+	if err != nil {
+		return nil, err
+	}
+	defer closeTable(t)
+
+	s := solver()
+	s.Checkpoint, s.CheckpointEvery = *checkpoint, *cpEvery
+	res, err := s.ShapleyTable(ctx, t)
+	if err != nil {
+		return res, err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// openTable opens the table file at path, building it from the data file first when it doesn't exist or was
+// built from another version of the data file.
+func openTable(path, dataPath string) (*shapley.Table, error) {
+	t, err := shapley.OpenTable(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return shapley.BuildTable(dataPath, path)
+	case err != nil:
+		return nil, err
+	}
+	same, err := t.BuiltFrom(dataPath)
+	if err == nil && same {
+		return t, nil
+	}
+	closeTable(t)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[WARN] table %s was built from another version of %s, rebuilding it", path, dataPath)
+
+	return shapley.BuildTable(dataPath, path)
+}
+
+func closeTable(t *shapley.Table) {
+	if err := t.Close(); err != nil {
+		log.Printf("[WARN] closing table: %v", err)
+	}
+}
+
+// solver is the shapley.Solver of the global flags.
+func solver() shapley.Solver {
+	s := shapley.Solver{WeightPrec: *weightPrec, Workers: *workers}
+	if *progress > 0 {
+		s.Progress = reportProgress(*progress)
+	}
+
+	return s
+}
+
+// reportProgress returns a shapley.Solver progress function that logs the processed coalitions and the estimated
+// time left at most once per interval.
+func reportProgress(interval time.Duration) func(done, total int) {
+	start := time.Now()
+	last := start
+	return func(done, total int) {
+		now := time.Now()
+		if now.Sub(last) < interval || done == 0 || done == total {
+			return
+		}
+		last = now
+		elapsed := now.Sub(start)
+		eta := time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+		log.Printf("[INFO] %d of %d coalitions (%.1f%%), ETA %s", done, total, 100*float64(done)/float64(total), eta.Round(time.Second))
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/razor-87/shapley"
+)
+
+// readRecords reads the rows of the data file of the global flags.
+func readRecords() ([][]string, error) {
+	f, err := os.Open(dataFile(""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open csv file, %w", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[WARN] closing file: %v", err)
+		}
+	}()
+
+	return shapley.ReadRecords(f)
+}
+
+// runExact is run in exact rational arithmetic, see shapley.Solver.Exact.
+func runExact() error {
+	start := time.Now()
+	records, err := readRecords()
+	if err != nil {
+		return err
+	}
+	res, err := solver().Exact(records)
+	if err != nil {
+		return err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	printExact(os.Stdout, res.Values)
+	fmt.Printf("Measure time: %s\n", elapsed)
+
+	return nil
+}
+
+// printExact writes values in ascending order with the rounded value and the exact fraction.
+func printExact(w io.Writer, values map[string]*big.Rat) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return values[names[i]].Cmp(values[names[j]]) < 0
+	})
+	for _, name := range names {
+		f, _ := values[name].Float64()
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, exact: %s\n", name, f, values[name].RatString())
+	}
+}
+
+// runInterval is run in interval arithmetic, see shapley.Solver.Interval.
+func runInterval() error {
+	start := time.Now()
+	records, err := readRecords()
+	if err != nil {
+		return err
+	}
+	res, err := solver().Interval(records)
+	if err != nil {
+		return err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	printIntervals(os.Stdout, res.Values)
+	fmt.Printf("Measure time: %s\n", elapsed)
+
+	return nil
+}
+
+// printIntervals writes the intervals in ascending order of their midpoints
+// and then the neighbours whose order isn't proven because their intervals overlap.
+func printIntervals(w io.Writer, values map[string]shapley.Interval) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return values[names[i]].Mid() < values[names[j]].Mid()
+	})
+
+	var ties []string
+	for k, name := range names {
+		v := values[name]
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, interval: [%.17g, %.17g]\n", name, v.Mid(), v.Lo, v.Hi)
+		if k > 0 && values[names[k-1]].Hi >= v.Lo {
+			ties = append(ties, names[k-1]+" ~ "+name)
+		}
+	}
+	if len(ties) == 0 {
+		fmt.Fprintln(w, "Ranking is provably strict")
+	} else {
+		fmt.Fprintf(w, "Unproven order: %s\n", strings.Join(ties, ", "))
+	}
+}
+
+// runUncertainty is run that propagates the uncertainty of the rows to the values, see shapley.Covariance.
+func runUncertainty() error {
+	records, err := readRecords()
+	if err != nil {
+		return err
+	}
+	g, err := shapley.ParseGame(records)
+	if err != nil {
+		return err
+	}
+	res, err := solver().Shapley(g)
+	if err != nil {
+		return err
+	}
+	if err := res.CheckNormalized(); err != nil {
+		return err
+	}
+
+	var covs []shapley.RowCov
+	if *stderrColumn {
+		if covs, err = shapley.RowStderrs(g, records); err != nil {
+			return err
+		}
+	}
+	if *covFile != "" {
+		cf, err := os.Open(*covFile)
+		if err != nil {
+			return fmt.Errorf("failed to open covariance file, %w", err)
+		}
+		defer cf.Close()
+		more, err := shapley.ReadCovariances(cf, g)
+		if err != nil {
+			return fmt.Errorf("failed to read covariances, %w", err)
+		}
+		for _, c := range more {
+			if *stderrColumn && c.S == c.T {
+				return fmt.Errorf("covariance file repeats the variance of %s given by -stderr", g.Name(c.S))
+			}
+		}
+		covs = append(covs, more...)
+	}
+
+	cov := shapley.Covariance(g, covs)
+	printUncertainty(os.Stdout, g.Players, res.Values, cov)
+
+	return nil
+}
+
+// printUncertainty writes the values with their standard errors in ascending order, then the covariances of all pairs.
+func printUncertainty(w io.Writer, players []string, values map[string]float64, cov [][]float64) {
+	order := make([]int, len(players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return values[players[order[a]]] < values[players[order[b]]]
+	})
+	for _, i := range order {
+		fmt.Fprintf(w, "Gene: %s, Shapley value: %f, standard error: %f\n", players[i], values[players[i]], math.Sqrt(cov[i][i]))
+	}
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			fmt.Fprintf(w, "Covariance: %s, %s: %g\n", players[i], players[j], cov[i][j])
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package shapley
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Coalition is a set of at most 64 players, bit i is player i of a game.
+type Coalition uint64
+
+// Singleton returns {i}.
+func Singleton(i int) Coalition {
+	return 1 << i
+}
+
+// Grand returns the coalition of the first n players.
+func Grand(n int) Coalition {
+	return Coalition(1)<<n - 1
+}
+
+// Has reports whether player i is a member of S.
+func (S Coalition) Has(i int) bool {
+	return S&(1<<i) != 0
+}
+
+// Size returns the number of members of S.
+func (S Coalition) Size() int {
+	return bits.OnesCount64(uint64(S))
+}
+
+// With returns S ∪ {i}.
+func (S Coalition) With(i int) Coalition {
+	return S | 1<<i
+}
+
+// Without returns S \ {i}.
+func (S Coalition) Without(i int) Coalition {
+	return S &^ (1 << i)
+}
+
+// Union returns S ∪ T.
+func (S Coalition) Union(T Coalition) Coalition {
+	return S | T
+}
+
+// Intersect returns S ∩ T.
+func (S Coalition) Intersect(T Coalition) Coalition {
+	return S & T
+}
+
+// Minus returns S \ T.
+func (S Coalition) Minus(T Coalition) Coalition {
+	return S &^ T
+}
+
+// SubsetOf reports whether S ⊆ T.
+func (S Coalition) SubsetOf(T Coalition) bool {
+	return S&^T == 0
+}
+
+// Members returns the players of S in ascending order.
+func (S Coalition) Members() []int {
+	members := make([]int, 0, S.Size())
+	S.ForEach(func(i int) {
+		members = append(members, i)
+	})
+
+	return members
+}
+
+// ForEach calls fn with every member of S in ascending order.
+func (S Coalition) ForEach(fn func(i int)) {
+	for T := S; T != 0; T &= T - 1 {
+		fn(bits.TrailingZeros64(uint64(T)))
+	}
+}
+
+// Subsets calls fn with every subset of S, from S down to the empty coalition, until fn returns false.
+func (S Coalition) Subsets(fn func(T Coalition) bool) {
+	for T := S; ; T = (T - 1) & S {
+		if !fn(T) || T == 0 {
+			return
+		}
+	}
+}
+
+// Supersets calls fn with every superset of S among the first n players, from S up to Grand(n), until fn returns false.
+func (S Coalition) Supersets(n int, fn func(T Coalition) bool) {
+	rest := Grand(n) &^ S
+	for U := Coalition(0); ; U = (U - rest) & rest {
+		if !fn(S|U) || U == rest {
+			return
+		}
+	}
+}
+
+// String formats S as {0, 2, 5}.
+func (S Coalition) String() string {
+	var b strings.Builder
+	b.WriteByte('{')
+	S.ForEach(func(i int) {
+		if b.Len() > 1 {
+			b.WriteString(", ")
+		}
+		b.WriteString(strconv.Itoa(i))
+	})
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// SizeK calls fn with every coalition of k of the first n players in ascending order until fn returns false.
+// The next coalition of the same size is Gosper's hack.
+func SizeK(n, k int, fn func(S Coalition) bool) {
+	if k < 0 || k > n {
+		return
+	}
+	if k == 0 {
+		fn(0)
+		return
+	}
+	for S := Grand(k); S <= Grand(n); {
+		if !fn(S) {
+			return
+		}
+		low := S & -S
+		ripple := S + low
+		if ripple == 0 { // k = n = 64
+			return
+		}
+		S = ripple | (S^ripple)>>2/low
+	}
+}
+
+// BigCoalition is a coalition of any number of players, bit i%64 of word i/64 is player i.
+type BigCoalition []uint64
+
+// NewBigCoalition returns the empty coalition of n players.
+func NewBigCoalition(n int) BigCoalition {
+	return make(BigCoalition, (n+63)/64)
+}
+
+// Has reports whether player i is a member of S.
+func (S BigCoalition) Has(i int) bool {
+	return S[i/64]&(1<<(i%64)) != 0
+}
+
+// Add puts player i in S.
+func (S BigCoalition) Add(i int) {
+	S[i/64] |= 1 << (i % 64)
+}
+
+// Remove takes player i out of S.
+func (S BigCoalition) Remove(i int) {
+	S[i/64] &^= 1 << (i % 64)
+}
+
+// Clear empties S.
+func (S BigCoalition) Clear() {
+	for w := range S {
+		S[w] = 0
+	}
+}
+
+// Size returns the number of members of S.
+func (S BigCoalition) Size() int {
+	var size int
+	for _, word := range S {
+		size += bits.OnesCount64(word)
+	}
+
+	return size
+}
+
+// Members returns the players of S in ascending order.
+func (S BigCoalition) Members() []int {
+	members := make([]int, 0, S.Size())
+	for w, word := range S {
+		for ; word != 0; word &= word - 1 {
+			members = append(members, 64*w+bits.TrailingZeros64(word))
+		}
+	}
+
+	return members
+}
+
+// Union returns S ∪ T of coalitions of the same players.
+func (S BigCoalition) Union(T BigCoalition) BigCoalition {
+	U := make(BigCoalition, len(S))
+	for w := range S {
+		U[w] = S[w] | T[w]
+	}
+
+	return U
+}
+
+// Intersect returns S ∩ T of coalitions of the same players.
+func (S BigCoalition) Intersect(T BigCoalition) BigCoalition {
+	U := make(BigCoalition, len(S))
+	for w := range S {
+		U[w] = S[w] & T[w]
+	}
+
+	return U
+}
+
+// SubsetOf reports whether S ⊆ T of coalitions of the same players.
+func (S BigCoalition) SubsetOf(T BigCoalition) bool {
+	for w := range S {
+		if S[w]&^T[w] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Small returns S as a Coalition, false when it has a player beyond the first 64.
+func (S BigCoalition) Small() (Coalition, bool) {
+	if len(S) == 0 {
+		return 0, true
+	}
+	for _, word := range S[1:] {
+		if word != 0 {
+			return 0, false
+		}
+	}
+
+	return Coalition(S[0]), true
+}
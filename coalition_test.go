@@ -0,0 +1,84 @@
+package shapley
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_Coalition(t *testing.T) {
+	S := Singleton(0).With(2).With(5)
+	if !S.Has(2) || S.Has(1) || S.Size() != 3 || S.Without(2).Has(2) {
+		t.Errorf("coalition %v", S)
+	}
+	if got := S.Members(); !reflect.DeepEqual(got, []int{0, 2, 5}) {
+		t.Errorf("Members() = %v, want [0 2 5]", got)
+	}
+	if got := S.String(); got != "{0, 2, 5}" {
+		t.Errorf("String() = %q", got)
+	}
+	if !S.Minus(Singleton(0)).SubsetOf(S) || S.SubsetOf(S.Intersect(Grand(3))) || S.Union(Grand(3)) != 0b100111 {
+		t.Errorf("set algebra of %v", S)
+	}
+	if Grand(64).Size() != 64 {
+		t.Errorf("Grand(64) = %b", Grand(64))
+	}
+
+	var subsets, supersets int
+	S.Subsets(func(T Coalition) bool {
+		if !T.SubsetOf(S) {
+			t.Errorf("Subsets() gave %v", T)
+		}
+		subsets++
+		return true
+	})
+	S.Supersets(7, func(T Coalition) bool {
+		if !S.SubsetOf(T) || !T.SubsetOf(Grand(7)) {
+			t.Errorf("Supersets() gave %v", T)
+		}
+		supersets++
+		return true
+	})
+	if subsets != 8 || supersets != 16 {
+		t.Errorf("Subsets() = %d, Supersets() = %d, want 8 and 16", subsets, supersets)
+	}
+}
+
+func Test_SizeK(t *testing.T) {
+	for _, tt := range []struct{ n, k int }{{5, 0}, {5, 2}, {10, 4}, {16, 16}, {64, 1}, {64, 63}} {
+		var count int
+		prev := Coalition(0)
+		SizeK(tt.n, tt.k, func(S Coalition) bool {
+			if S.Size() != tt.k || !S.SubsetOf(Grand(tt.n)) || (count > 0 && S <= prev) {
+				t.Errorf("SizeK(%d, %d) gave %v after %v", tt.n, tt.k, S, prev)
+			}
+			prev = S
+			count++
+			return true
+		})
+		if want := binomial(tt.n, tt.k); float64(count) != want {
+			t.Errorf("SizeK(%d, %d) gave %d coalitions, want %v", tt.n, tt.k, count, want)
+		}
+	}
+}
+
+func Test_BigCoalition(t *testing.T) {
+	S := NewBigCoalition(200)
+	S.Add(3)
+	S.Add(130)
+	T := NewBigCoalition(200)
+	T.Add(130)
+	T.Add(199)
+	if got := S.Union(T).Members(); !reflect.DeepEqual(got, []int{3, 130, 199}) {
+		t.Errorf("Union() = %v", got)
+	}
+	if got := S.Intersect(T).Members(); !reflect.DeepEqual(got, []int{130}) {
+		t.Errorf("Intersect() = %v", got)
+	}
+	if _, ok := S.Small(); ok {
+		t.Errorf("Small() of %v ok", S.Members())
+	}
+	S.Remove(130)
+	if c, ok := S.Small(); !ok || c != Singleton(3) {
+		t.Errorf("Small() = %v, %v", c, ok)
+	}
+}
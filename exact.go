@@ -0,0 +1,142 @@
+package shapley
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// ExactResult is the Shapley value of every player in exact rational arithmetic.
+type ExactResult struct {
+	Values map[string]*big.Rat
+	Sum    *big.Rat
+}
+
+// CheckNormalized fails unless the values sum to one.
+func (r *ExactResult) CheckNormalized() error {
+	if f, _ := r.Sum.Float64(); notEqualsOne(f) {
+		return fmt.Errorf("sum of Shapley values isn't equal to one, %s", r.Sum.RatString())
+	}
+
+	return nil
+}
+
+// Exact computes the Shapley values of the game of records in exact rational arithmetic: the dividends are read
+// as decimal fractions, so the values are exact for the data as written and efficiency holds without a tolerance.
+func (s Solver) Exact(records [][]string) (*ExactResult, error) {
+	if err := checkRecords(records); err != nil {
+		return nil, err
+	}
+	players, bitset, worths, err := handleExact(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to handle data, %w", err)
+	}
+
+	sValues, checkSum := shapleyExact(players, bitset, worths)
+	if grand := worths[len(worths)-1]; checkSum.Cmp(grand) != 0 {
+		return nil, fmt.Errorf("sum of Shapley values %s isn't equal to v(N) %s", checkSum.RatString(), grand.RatString())
+	}
+
+	return &ExactResult{Values: sValues, Sum: checkSum}, nil
+}
+
+// handleExact is handle with rational dividends. The worths are a dense table indexed by coalition,
+// summed from the dividends by the zeta transform in n·2^n additions.
+func handleExact(records [][]string) (players []string, bitset []Coalition, worths []*big.Rat, err error) {
+	players, index, err := rowPlayers(records)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bitset = make([]Coalition, len(players))
+	for i := range bitset {
+		bitset[i] = Singleton(i)
+	}
+
+	worths = make([]*big.Rat, 1<<len(players))
+	for S := range worths {
+		worths[S] = new(big.Rat)
+	}
+	for _, rec := range records {
+		coalition, err := parseCoalition(rec[0], index)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if _, ok := worths[coalition].SetString(strings.TrimSpace(rec[1])); !ok {
+			return nil, nil, nil, fmt.Errorf("failed to convert string to rational, %q", rec[1])
+		}
+	}
+	for _, bs := range bitset {
+		for S := range worths {
+			if Coalition(S)&bs != 0 {
+				worths[S].Add(worths[S], worths[Coalition(S)&^bs])
+			}
+		}
+	}
+
+	return players, bitset, worths, nil
+}
+
+// ratWeights returns the exact Shapley weights k!(n-k-1)!/n! for k = 0..n-1.
+func ratWeights(n int) []*big.Rat {
+	factorials := make([]*big.Int, n+1)
+	factorials[0] = big.NewInt(1)
+	for i := 1; i <= n; i++ {
+		factorials[i] = new(big.Int).Mul(factorials[i-1], big.NewInt(int64(i)))
+	}
+
+	wsn := make([]*big.Rat, n)
+	for k := range wsn {
+		wsn[k] = new(big.Rat).SetFrac(new(big.Int).Mul(factorials[k], factorials[n-k-1]), factorials[n])
+	}
+
+	return wsn
+}
+
+// shapleyExact is shapley over a dense table of rational worths. The marginal contributions are summed
+// per coalition size first, so every player needs only n multiplications by a weight.
+func shapleyExact(players []string, bitset []Coalition, worths []*big.Rat) (map[string]*big.Rat, *big.Rat) {
+	n := len(players)
+	weights := ratWeights(n)
+	vector := make([]*big.Rat, n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i, bs := range bitset {
+		go func(i int, bs Coalition) {
+			defer wg.Done()
+
+			bySize := make([]*big.Rat, n)
+			for k := range bySize {
+				bySize[k] = new(big.Rat)
+			}
+			contrib := new(big.Rat)
+			for S := range worths {
+				if Coalition(S)&bs != 0 {
+					continue
+				}
+				// Marginal contribution = v(S U {i})-v(S)
+				contrib.Sub(worths[Coalition(S)|bs], worths[S])
+				k := Coalition(S).Size()
+				bySize[k].Add(bySize[k], contrib)
+			}
+
+			value := new(big.Rat)
+			for k, sum := range bySize {
+				// Weight = |S|!(n-|S|-1)!/n!
+				value.Add(value, sum.Mul(sum, weights[k]))
+			}
+			vector[i] = value
+		}(i, bs)
+	}
+	wg.Wait()
+
+	vSum := new(big.Rat)
+	sValues := make(map[string]*big.Rat, n)
+	for i, value := range vector {
+		vSum.Add(vSum, value)
+		sValues[players[i]] = value
+	}
+
+	return sValues, vSum
+}
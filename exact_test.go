@@ -0,0 +1,33 @@
+package shapley
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_shapleyExact(t *testing.T) {
+	players, bitset, worths, err := handleExact(mockRecords())
+	if err != nil {
+		t.Fatalf("handleExact() error = %v", err)
+	}
+	if got := worths[0b111].RatString(); got != "1" {
+		t.Errorf("handleExact() v(N) = %s, want 1", got)
+	}
+
+	got, checkSum := shapleyExact(players, bitset, worths)
+	want := map[string]string{"Google": "9/20", "Meta": "43/200", "Microsoft": "67/200"}
+	for key, wantValue := range want {
+		if value := got[key].RatString(); value != wantValue {
+			t.Errorf("%s: wantValue = %s, gotValue = %s", key, wantValue, value)
+		}
+	}
+	if checkSum.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Errorf("shapleyExact() checkSum = %s, want 1", checkSum.RatString())
+	}
+}
+
+func Test_handleExact(t *testing.T) {
+	if _, _, _, err := handleExact([][]string{{"A", "0.5"}, {"A B", "x"}}); err == nil {
+		t.Errorf("handleExact() error = nil, want error")
+	}
+}
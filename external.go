@@ -0,0 +1,247 @@
+package shapley
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// External evaluates coalitions with a child process. Every request is a line of JSON with a batch of feature masks,
+//
+//	{"id": 1, "background": "train.csv", "masks": [[1, 0, 1], [0, 1, 1]]}
+//
+// where mask[i] is 1 when player i is in the coalition, and the answer is a line with a value per mask,
+//
+//	{"id": 1, "values": [0.25, 0.5]}
+//
+// or {"id": 1, "error": "..."}. A failed batch restarts the process and is sent again up to Retries times.
+type External struct {
+	stdin      io.WriteCloser
+	stdout     *bufio.Scanner
+	cmd        *exec.Cmd
+	Command    []string // program and its arguments
+	Background string   // reference to the background dataset, passed with every batch
+	BatchSize  int      // masks per request
+	Retries    int      // restarts of the process per failed batch
+	nextID     int
+	mu         sync.Mutex
+}
+
+type externalRequest struct {
+	Background string  `json:"background,omitempty"`
+	Masks      [][]int `json:"masks"`
+	ID         int     `json:"id"`
+}
+
+type externalResponse struct {
+	Error  string    `json:"error,omitempty"`
+	Values []float64 `json:"values"`
+	ID     int       `json:"id"`
+}
+
+// Evaluate returns the values of coalitions of n players, in batches of BatchSize.
+func (e *External) Evaluate(ctx context.Context, n int, coalitions []Coalition) ([]float64, error) {
+	masks := make([][]int, len(coalitions))
+	for k, S := range coalitions {
+		masks[k] = make([]int, n)
+		for i := range masks[k] {
+			if S.Has(i) {
+				masks[k][i] = 1
+			}
+		}
+	}
+
+	size := e.BatchSize
+	if size <= 0 {
+		size = len(masks)
+	}
+	values := make([]float64, 0, len(masks))
+	for start := 0; start < len(masks); start += size {
+		end := start + size
+		if end > len(masks) {
+			end = len(masks)
+		}
+		batch, err := e.evaluateBatch(ctx, masks[start:end])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, batch...)
+	}
+
+	return values, nil
+}
+
+// Value is a ValueFunc of single coalitions of n players for an Oracle.
+func (e *External) Value(n int) ValueFunc {
+	return func(ctx context.Context, S Coalition) (float64, error) {
+		values, err := e.Evaluate(ctx, n, []Coalition{S})
+		if err != nil {
+			return 0, err
+		}
+		return values[0], nil
+	}
+}
+
+// BigValue is a BigValueFunc of single coalitions of n players for SampleShapley, n may exceed MaxPlayers.
+func (e *External) BigValue(n int) BigValueFunc {
+	return func(ctx context.Context, S BigCoalition) (float64, error) {
+		mask := make([]int, n)
+		for i := range mask {
+			if S.Has(i) {
+				mask[i] = 1
+			}
+		}
+		values, err := e.evaluateBatch(ctx, [][]int{mask})
+		if err != nil {
+			return 0, err
+		}
+		return values[0], nil
+	}
+}
+
+// Game evaluates every coalition of players in Gray code order.
+func (e *External) Game(ctx context.Context, players []string) (*Game, error) {
+	if n := len(players); n == 0 || n > MaxPlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+
+	g := NewGame(players)
+	coalitions := make([]Coalition, g.Grand())
+	for k := range coalitions {
+		coalitions[k] = gray(k + 1)
+	}
+	values, err := e.Evaluate(ctx, len(players), coalitions)
+	if err != nil {
+		return nil, err
+	}
+	for k, S := range coalitions {
+		g.Worths[S] = values[k]
+	}
+
+	return g, nil
+}
+
+func (e *External) evaluateBatch(ctx context.Context, masks [][]int) ([]float64, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	for attempt := 0; attempt <= e.Retries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		e.nextID++
+		values, err := e.roundTrip(ctx, externalRequest{ID: e.nextID, Background: e.Background, Masks: masks})
+		if err == nil {
+			return values, nil
+		}
+		errs = append(errs, fmt.Errorf("attempt %d: %w", attempt+1, err))
+		if e.cmd != nil {
+			e.cmd.Process.Kill()
+			e.stop()
+		}
+	}
+
+	return nil, fmt.Errorf("failed to evaluate batch, %w", errors.Join(errs...))
+}
+
+// roundTrip sends a request to the process, starting it when it isn't running, and reads its answer.
+// The process is killed when ctx is done before the answer.
+func (e *External) roundTrip(ctx context.Context, req externalRequest) ([]float64, error) {
+	if e.cmd == nil {
+		if err := e.start(); err != nil {
+			return nil, err
+		}
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func(p *os.Process) {
+		select {
+		case <-ctx.Done():
+			p.Kill()
+		case <-done:
+		}
+	}(e.cmd.Process)
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request, %w", err)
+	}
+	if _, err := e.stdin.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write request, %w", err)
+	}
+	if !e.stdout.Scan() {
+		if err := e.stdout.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read response, %w", err)
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	var resp externalResponse
+	if err := json.Unmarshal(e.stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response, %w", err)
+	}
+	switch {
+	case resp.ID != req.ID:
+		return nil, fmt.Errorf("response %d to request %d", resp.ID, req.ID)
+	case resp.Error != "":
+		return nil, errors.New(resp.Error)
+	case len(resp.Values) != len(req.Masks):
+		return nil, fmt.Errorf("%d values for %d masks", len(resp.Values), len(req.Masks))
+	}
+
+	return resp.Values, nil
+}
+
+func (e *External) start() error {
+	if len(e.Command) == 0 {
+		return errors.New("no external command")
+	}
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdin, %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout, %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s, %w", e.Command[0], err)
+	}
+
+	e.cmd, e.stdin = cmd, stdin
+	e.stdout = bufio.NewScanner(stdout)
+	e.stdout.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	return nil
+}
+
+// stop closes the stdin of the process and waits for it to exit.
+func (e *External) stop() {
+	if e.cmd == nil {
+		return
+	}
+	e.stdin.Close()
+	if err := e.cmd.Wait(); err != nil {
+		log.Printf("[WARN] external process: %v", err)
+	}
+	e.cmd = nil
+}
+
+// Close stops the process.
+func (e *External) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stop()
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package shapley
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestExternalHelper is the child process of Test_External: it answers every batch with v(S) = |S|²,
+// and exits without an answer to its first request when SHAPLEY_FAIL_ONCE names a file that doesn't exist yet.
+func TestExternalHelper(t *testing.T) {
+	if os.Getenv("SHAPLEY_HELPER") != "1" {
+		t.Skip("child process of Test_External")
+	}
+	sc := bufio.NewScanner(os.Stdin)
+	for sc.Scan() {
+		if marker := os.Getenv("SHAPLEY_FAIL_ONCE"); marker != "" {
+			if _, err := os.Stat(marker); os.IsNotExist(err) {
+				os.WriteFile(marker, nil, 0o600)
+				os.Exit(1)
+			}
+		}
+		var req externalRequest
+		if err := json.Unmarshal(sc.Bytes(), &req); err != nil {
+			fmt.Printf("{\"id\": 0, \"error\": %q}\n", err.Error())
+			continue
+		}
+		resp := externalResponse{ID: req.ID, Values: make([]float64, len(req.Masks))}
+		for k, mask := range req.Masks {
+			var size float64
+			for _, bit := range mask {
+				size += float64(bit)
+			}
+			resp.Values[k] = size * size
+		}
+		line, _ := json.Marshal(resp)
+		fmt.Println(string(line))
+	}
+	os.Exit(0)
+}
+
+func Test_External(t *testing.T) {
+	t.Setenv("SHAPLEY_HELPER", "1")
+	t.Setenv("SHAPLEY_FAIL_ONCE", filepath.Join(t.TempDir(), "failed"))
+	e := &External{
+		Command:    []string{os.Args[0], "-test.run=^TestExternalHelper$"},
+		Background: "background.csv",
+		BatchSize:  7,
+		Retries:    1,
+	}
+	defer e.Close()
+
+	players := NumberedPlayers("P", 4)
+	g, err := e.Game(context.Background(), players)
+	if err != nil {
+		t.Fatalf("Game() error = %v", err)
+	}
+	if got := g.Worths[0b1011]; got != 9 {
+		t.Errorf("Game()[1011] = %v, want 9", got)
+	}
+	res, err := Shapley(g)
+	if err != nil {
+		t.Fatalf("Shapley() error = %v", err)
+	}
+	for _, player := range players {
+		if got := res.Values[player]; math.Abs(got-4) > 1e-9 {
+			t.Errorf("Shapley()[%s] = %v, want 4", player, got)
+		}
+	}
+
+	o := NewOracle(players, e.Value(len(players)), 2)
+	if got, err := o.Worth(context.Background(), 0b111); err != nil || got != 9 {
+		t.Errorf("Worth() = %v, %v, want 9", got, err)
+	}
+
+	many := NumberedPlayers("P", 70)
+	sample, err := SampleShapley(context.Background(), len(many), e.BigValue(len(many)), 10, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("SampleShapley() error = %v", err)
+	}
+	// the contributions 2k+1 of a player joining k others average to n
+	for i, got := range sample.Values {
+		if math.Abs(got-70) > 5*sample.Stderrs[i]+1e-9 {
+			t.Errorf("SampleShapley()[%s] = %v ± %v, want 70", many[i], got, sample.Stderrs[i])
+		}
+	}
+
+	broken := &External{Command: []string{os.Args[0], "-test.run=^TestExternalHelper$"}, Retries: 1}
+	t.Setenv("SHAPLEY_HELPER", "0")
+	if _, err := broken.Evaluate(context.Background(), 2, []Coalition{1}); err == nil {
+		t.Errorf("Evaluate() error = nil, want error")
+	}
+}
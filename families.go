@@ -0,0 +1,142 @@
+package shapley
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Synthetic game families for benchmarks and tests. Every family draws Harsanyi dividends indexed by coalition
+// that sum to one, so the Shapley values of a generated game sum to one as calc expects.
+
+// sampleGenes are the genes of the original data/N files, larger games continue with numbered genes.
+var sampleGenes = []string{
+	"M55150", "U32944", "U50136", "X95735", "M92287", "X59350", "M28130", "M31211", "D88422", "U46499",
+	"X59417", "Y00787", "M84526", "U46751", "HG1322", "L47738", "M80254", "D88270", "M62762", "U05259",
+	"M84371", "U26266", "M22324", "M69043", "U97105", "M63838", "M16038", "M23197", "M89957", "M63138",
+	"J05243", "X70070", "X82240", "D43948", "M83667", "X15414", "X74570", "U40369", "D83785", "U10323",
+}
+
+// GenePlayers returns n gene names, the sample genes first and then G<i>.
+func GenePlayers(n int) []string {
+	players := make([]string, n)
+	for i := range players {
+		if i < len(sampleGenes) {
+			players[i] = sampleGenes[i]
+		} else {
+			players[i] = fmt.Sprintf("G%d", i+1)
+		}
+	}
+
+	return players
+}
+
+// FamilyParams tune the families, unused fields are ignored.
+type FamilyParams struct {
+	Alpha   float64 // Dirichlet concentration
+	Density float64 // share of non-zero dividends of sparse
+	Noise   float64 // standard deviation of the interactions of noisy
+	K       int     // largest coalition with a dividend in kadditive
+}
+
+// GenerateFamily returns the dividends of a game of the named family on n players.
+func GenerateFamily(name string, n int, p FamilyParams, rng *rand.Rand) ([]float64, error) {
+	d := make([]float64, 1<<n)
+	switch name {
+	case "dirichlet":
+		// As scripts/generate_data.py: sorted Dirichlet weights, smaller coalitions get smaller dividends.
+		coalitions := make([]int, 0, len(d)-1)
+		for S := 1; S < len(d); S++ {
+			coalitions = append(coalitions, S)
+		}
+		sort.SliceStable(coalitions, func(i, j int) bool {
+			return Coalition(coalitions[i]).Size() < Coalition(coalitions[j]).Size()
+		})
+		weights := dirichlet(rng, len(coalitions), p.Alpha)
+		sort.Float64s(weights)
+		for i, S := range coalitions {
+			d[S] = weights[i]
+		}
+	case "sparse":
+		var support []int
+		for S := 1; S < len(d); S++ {
+			if Coalition(S).Size() == 1 || rng.Float64() < p.Density {
+				support = append(support, S)
+			}
+		}
+		for i, w := range dirichlet(rng, len(support), p.Alpha) {
+			d[support[i]] = w
+		}
+	case "kadditive":
+		if p.K < 1 {
+			return nil, fmt.Errorf("k must be positive, %d", p.K)
+		}
+		var support []int
+		for S := 1; S < len(d); S++ {
+			if Coalition(S).Size() <= p.K {
+				support = append(support, S)
+			}
+		}
+		for i, w := range dirichlet(rng, len(support), p.Alpha) {
+			d[support[i]] = w
+		}
+	case "noisy":
+		// Additive game plus small interactions, the singletons absorb the interactions to keep v(N) = 1.
+		var interactions float64
+		for S := 1; S < len(d); S++ {
+			if Coalition(S).Size() > 1 {
+				d[S] = rng.NormFloat64() * p.Noise / math.Sqrt(float64(len(d)))
+				interactions += d[S]
+			}
+		}
+		for i, w := range dirichlet(rng, n, p.Alpha) {
+			d[Singleton(i)] = w * (1 - interactions)
+		}
+	default:
+		return nil, fmt.Errorf("unknown game family %q", name)
+	}
+
+	return d, nil
+}
+
+// dirichlet draws a point of the (size-1)-simplex from Dirichlet(alpha, ..., alpha).
+func dirichlet(rng *rand.Rand, size int, alpha float64) []float64 {
+	weights := make([]float64, size)
+	var sum float64
+	for i := range weights {
+		weights[i] = gammaVariate(rng, alpha)
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+
+	return weights
+}
+
+// gammaVariate draws from Gamma(alpha, 1) by Marsaglia and Tsang's method.
+func gammaVariate(rng *rand.Rand, alpha float64) float64 {
+	if alpha == 1 {
+		return rng.ExpFloat64()
+	}
+	if alpha < 1 {
+		// Gamma(alpha) = Gamma(alpha+1)·U^(1/alpha)
+		return gammaVariate(rng, alpha+1) * math.Pow(rng.Float64(), 1/alpha)
+	}
+
+	d := alpha - 1./3
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if math.Log(u) < 0.5*x*x+d-d*v+d*math.Log(v) {
+			return d * v
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package shapley
+
+import (
+	"bytes"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func Test_generateFamily(t *testing.T) {
+	tests := []struct {
+		name  string
+		check func(S int, d float64) bool
+		p     FamilyParams
+	}{
+		{name: "dirichlet", p: FamilyParams{Alpha: 1}, check: func(_ int, d float64) bool { return d > 0 }},
+		{name: "sparse", p: FamilyParams{Alpha: 0.5, Density: 0.2}, check: func(_ int, d float64) bool { return d >= 0 }},
+		{name: "kadditive", p: FamilyParams{Alpha: 2, K: 2}, check: func(S int, d float64) bool {
+			return (bits.OnesCount(uint(S)) <= 2) == (d > 0)
+		}},
+		{name: "noisy", p: FamilyParams{Alpha: 1, Noise: 0.01}, check: func(S int, d float64) bool {
+			return bits.OnesCount(uint(S)) > 1 || d > 0
+		}},
+	}
+	const n = 8
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := GenerateFamily(tt.name, n, tt.p, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatalf("GenerateFamily() error = %v", err)
+			}
+			again, _ := GenerateFamily(tt.name, n, tt.p, rand.New(rand.NewSource(1)))
+			if !reflect.DeepEqual(d, again) {
+				t.Errorf("GenerateFamily() isn't reproducible with the same seed")
+			}
+
+			var sum float64
+			for S := 1; S < len(d); S++ {
+				sum += d[S]
+				if !tt.check(S, d[S]) {
+					t.Errorf("dividend of %b = %v", S, d[S])
+				}
+			}
+			if notEqualsOne(sum) {
+				t.Errorf("sum of dividends = %v, want 1", sum)
+			}
+
+			var buf bytes.Buffer
+			if err := WriteDividends(&buf, GenePlayers(n), d); err != nil {
+				t.Fatalf("WriteDividends() error = %v", err)
+			}
+			records, err := prepare(&buf, n)
+			if err != nil {
+				t.Fatalf("prepare() error = %v", err)
+			}
+			players, bitset, worths, err := handle(records)
+			if err != nil {
+				t.Fatalf("handle() error = %v", err)
+			}
+			if _, checkSum := shapley(players, bitset, worths); math.IsNaN(checkSum) || notEqualsOne(checkSum) {
+				t.Errorf("shapley() checkSum = %v, want 1", checkSum)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package shapley
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Game is a TU game: players sorted by name and the worth of every non-empty coalition.
+// Bit i of a coalition is Players[i], a missing coalition is worth zero.
+type Game struct {
+	Worths  map[Coalition]float64
+	Players []string
+}
+
+// NewGame returns a game of players where every coalition is worth zero.
+func NewGame(players []string) *Game {
+	return &Game{Players: players, Worths: make(map[Coalition]float64, 1<<len(players))}
+}
+
+// bits returns the bit of every player.
+func (g *Game) bits() []Coalition {
+	bitset := make([]Coalition, len(g.Players))
+	for i := range bitset {
+		bitset[i] = Singleton(i)
+	}
+
+	return bitset
+}
+
+// Grand returns the grand coalition of all players.
+func (g *Game) Grand() Coalition {
+	return Grand(len(g.Players))
+}
+
+// Indices returns the positions of names in g.Players in ascending order.
+func (g *Game) Indices(names []string) ([]int, error) {
+	idx := make([]int, 0, len(names))
+	for _, name := range names {
+		i := sort.SearchStrings(g.Players, name)
+		if i == len(g.Players) || g.Players[i] != name {
+			return nil, fmt.Errorf("unknown player %q", name)
+		}
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	for k := 1; k < len(idx); k++ {
+		if idx[k] == idx[k-1] {
+			return nil, fmt.Errorf("duplicate player %q", g.Players[idx[k]])
+		}
+	}
+
+	return idx, nil
+}
+
+// Name formats a coalition as {A, B}.
+func (g *Game) Name(S Coalition) string {
+	names := make([]string, 0, S.Size())
+	S.ForEach(func(i int) {
+		names = append(names, g.Players[i])
+	})
+
+	return "{" + strings.Join(names, ", ") + "}"
+}
+
+// expand maps a coalition of the players idx to a coalition of the game they were taken from.
+func expand(S Coalition, idx []int) Coalition {
+	var T Coalition
+	S.ForEach(func(k int) {
+		T |= Singleton(idx[k])
+	})
+
+	return T
+}
+
+// Dual returns v*(S) = v(N) - v(N\S).
+func Dual(g *Game) *Game {
+	d := NewGame(g.Players)
+	N := g.Grand()
+	for s := 1; s <= int(N); s++ {
+		S := Coalition(s)
+		d.Worths[S] = g.Worths[N] - g.Worths[N&^S]
+	}
+
+	return d
+}
+
+// ZeroNormalize returns v0(S) = v(S) - Σ_{i∈S} v({i}).
+func ZeroNormalize(g *Game) *Game {
+	b := make([]float64, len(g.Players))
+	for i, bs := range g.bits() {
+		b[i] = -g.Worths[bs]
+	}
+
+	return Strategic(g, 1, b)
+}
+
+// Strategic returns the strategically equivalent game a·v(S) + Σ_{i∈S} b_i.
+func Strategic(g *Game, a float64, b []float64) *Game {
+	e := NewGame(g.Players)
+	N := g.Grand()
+	bitset := g.bits()
+	for s := 1; s <= int(N); s++ {
+		S := Coalition(s)
+		worth := a * g.Worths[S]
+		for i, bs := range bitset {
+			if S&bs != 0 {
+				worth += b[i]
+			}
+		}
+		e.Worths[S] = worth
+	}
+
+	return e
+}
+
+// Combine returns the linear combination Σ coefs[k]·games[k] of games over the same players.
+func Combine(coefs []float64, games ...*Game) (*Game, error) {
+	if len(coefs) != len(games) || len(games) == 0 {
+		return nil, fmt.Errorf("%d coefficients for %d games", len(coefs), len(games))
+	}
+	for _, g := range games[1:] {
+		if strings.Join(g.Players, " ") != strings.Join(games[0].Players, " ") {
+			return nil, errors.New("games have different players")
+		}
+	}
+
+	c := NewGame(games[0].Players)
+	N := c.Grand()
+	for s := 1; s <= int(N); s++ {
+		S := Coalition(s)
+		var worth float64
+		for k, g := range games {
+			worth += coefs[k] * g.Worths[S]
+		}
+		c.Worths[S] = worth
+	}
+
+	return c, nil
+}
+
+// Restrict returns the subgame on the given players: v_T(S) = v(S) for S ⊆ T.
+func Restrict(g *Game, players []string) (*Game, error) {
+	idx, err := g.Indices(players)
+	if err != nil {
+		return nil, err
+	}
+
+	return subgame(g, idx), nil
+}
+
+func subgame(g *Game, idx []int) *Game {
+	names := make([]string, len(idx))
+	for k, i := range idx {
+		names[k] = g.Players[i]
+	}
+	r := NewGame(names)
+	T := r.Grand()
+	for s := 1; s <= int(T); s++ {
+		S := Coalition(s)
+		r.Worths[S] = g.Worths[expand(S, idx)]
+	}
+
+	return r
+}
+
+// Marginal returns the game of player's marginal contributions on the others: m(S) = v(S∪{i}) - v(S).
+func Marginal(g *Game, player string) (*Game, error) {
+	idx, err := g.Indices([]string{player})
+	if err != nil {
+		return nil, err
+	}
+	bs := g.bits()[idx[0]]
+	others := make([]int, 0, len(g.Players)-1)
+	for i := range g.Players {
+		if i != idx[0] {
+			others = append(others, i)
+		}
+	}
+
+	m := subgame(g, others)
+	T := m.Grand()
+	for s := 1; s <= int(T); s++ {
+		S := Coalition(s)
+		orig := expand(S, others)
+		m.Worths[S] = g.Worths[orig|bs] - g.Worths[orig]
+	}
+
+	return m, nil
+}
+
+// Reduced returns the Hart–Mas-Colell reduced game on the given players T:
+// v_T(S) = v(S∪(N\T)) - Σ_{j∈N\T} φ_j(v restricted to S∪(N\T)).
+// The Shapley value is consistent with it: φ_i(v_T) = φ_i(v) for every i in T.
+func Reduced(g *Game, players []string) (*Game, error) {
+	idx, err := g.Indices(players)
+	if err != nil {
+		return nil, err
+	}
+
+	r := NewGame(make([]string, len(idx)))
+	for k, i := range idx {
+		r.Players[k] = g.Players[i]
+	}
+	T := expand(r.Grand(), idx)
+	rest := g.Grand().Minus(T)
+	for s := 1; s <= int(r.Grand()); s++ {
+		S := Coalition(s)
+		U := expand(S, idx).Union(rest)
+		sub := subgame(g, U.Members())
+		sValues, _ := shapley(sub.Players, sub.bits(), sub.Worths)
+
+		worth := g.Worths[U]
+		rest.ForEach(func(i int) {
+			worth -= sValues[g.Players[i]]
+		})
+		r.Worths[S] = worth
+	}
+
+	return r, nil
+}
+
+// Dividends returns the Harsanyi dividends of g (the Möbius transform of its worths) as a dense table.
+func Dividends(g *Game) []float64 {
+	d := make([]float64, 1<<len(g.Players))
+	for S, worth := range g.Worths {
+		d[S] = worth
+	}
+	for _, bs := range g.bits() {
+		for S := range d {
+			if Coalition(S)&bs != 0 {
+				d[S] -= d[Coalition(S)&^bs]
+			}
+		}
+	}
+
+	return d
+}
+
+// WriteGame writes g in the data/N format read by prepare and handle.
+func WriteGame(w io.Writer, g *Game) error {
+	return WriteDividends(w, g.Players, Dividends(g))
+}
+
+// WriteDividends writes one "<players>,<dividend>" row per non-empty coalition of players, d is indexed by coalition.
+// Coalitions go by size and in lexicographic order within a size, so the grand coalition comes last.
+func WriteDividends(w io.Writer, players []string, d []float64) error {
+	n := len(players)
+	bw := bufio.NewWriter(w)
+	names := make([]string, 0, n)
+	for k := 1; k <= n; k++ {
+		SizeK(n, k, func(S Coalition) bool {
+			names = names[:0]
+			S.ForEach(func(i int) {
+				names = append(names, players[i])
+			})
+			fmt.Fprintf(bw, "%s,%s\n", strings.Join(names, " "), strconv.FormatFloat(d[S], 'g', -1, 64))
+			return true
+		})
+	}
+
+	return bw.Flush()
+}
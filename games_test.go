@@ -0,0 +1,99 @@
+package shapley
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func mockGame() *Game {
+	return &Game{Players: mockPlayers(), Worths: mockWorths()}
+}
+
+func assertValues(t *testing.T, got, want map[string]float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %d values, want %d", len(got), len(want))
+	}
+	for key, wantValue := range want {
+		if value := got[key]; math.Abs(wantValue-value) > 1e-9 {
+			t.Errorf("%s: wantValue = %v, gotValue = %v", key, wantValue, value)
+		}
+	}
+}
+
+func Test_transformations(t *testing.T) {
+	sValues := map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335}
+	g := mockGame()
+	tests := []struct {
+		transform func() (*Game, error)
+		want      map[string]float64
+		name      string
+	}{
+		{
+			name:      "dual",
+			transform: func() (*Game, error) { return Dual(g), nil },
+			want:      sValues,
+		},
+		{
+			name:      "normalize",
+			transform: func() (*Game, error) { return ZeroNormalize(g), nil },
+			want:      map[string]float64{"Google": 0.45 - 0.18, "Meta": 0.215 - 0.04, "Microsoft": 0.335 - 0.08},
+		},
+		{
+			name:      "strategic",
+			transform: func() (*Game, error) { return Strategic(g, 2, []float64{1, 0, -1}), nil },
+			want:      map[string]float64{"Google": 1.9, "Meta": 0.43, "Microsoft": -0.33},
+		},
+		{
+			name:      "sum",
+			transform: func() (*Game, error) { return Combine([]float64{2, -1}, g, Dual(g)) },
+			want:      sValues,
+		},
+		{
+			name:      "restrict",
+			transform: func() (*Game, error) { return Restrict(g, []string{"Meta", "Google"}) },
+			want:      map[string]float64{"Google": 0.23, "Meta": 0.09},
+		},
+		{
+			name:      "marginal",
+			transform: func() (*Game, error) { return Marginal(g, "Google") },
+			want:      map[string]float64{"Meta": 0.325, "Microsoft": 0.485},
+		},
+		{
+			name:      "reduced",
+			transform: func() (*Game, error) { return Reduced(g, []string{"Meta", "Microsoft"}) },
+			want:      map[string]float64{"Meta": 0.215, "Microsoft": 0.335},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.transform()
+			if err != nil {
+				t.Fatalf("transform error = %v", err)
+			}
+			sv, _ := shapley(got.Players, got.bits(), got.Worths)
+			assertValues(t, sv, tt.want)
+		})
+	}
+}
+
+func Test_writeGame(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteGame(&buf, mockGame()); err != nil {
+		t.Fatalf("WriteGame() error = %v", err)
+	}
+	records, err := prepare(&buf, 3)
+	if err != nil {
+		t.Fatalf("prepare() error = %v", err)
+	}
+	_, _, worths, err := handle(records)
+	if err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+	for key, wantValue := range mockWorths() {
+		if value := worths[key]; math.Abs(wantValue-value) > 1e-9 {
+			t.Errorf("wantValue = %v, gotValue = %v", wantValue, value)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package shapley
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// GeneSet is a row of a GMT file: a name, a description and the genes of the set.
+type GeneSet struct {
+	Name, Description string
+	Genes             []string
+}
+
+// Enrichment is the result of a preranked GSEA of a gene set.
+type Enrichment struct {
+	Name   string
+	Size   int // genes of the set found in the ranking
+	ES     float64
+	NES    float64
+	PValue float64
+	FDR    float64
+}
+
+// ParseGMT reads tab-separated "<name>\t<description>\t<gene>..." rows.
+func ParseGMT(r io.Reader) ([]GeneSet, error) {
+	var sets []GeneSet
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimRight(sc.Text(), "\r\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("line %d: want a name, a description and genes", line)
+		}
+		set := GeneSet{Name: fields[0], Description: fields[1]}
+		for _, gene := range fields[2:] {
+			if gene = strings.TrimSpace(gene); gene != "" {
+				set.Genes = append(set.Genes, gene)
+			}
+		}
+		sets = append(sets, set)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens, %w", err)
+	}
+
+	return sets, nil
+}
+
+// enrichmentScore is the weighted Kolmogorov–Smirnov running sum of Subramanian et al. (2005) over ranked values
+// in descending order: a hit adds |r_j|^p / Σ_hits |r|^p, a miss subtracts 1/(N - hits).
+// The score is the largest deviation from zero.
+func enrichmentScore(ranked []float64, hits []bool, p float64) float64 {
+	var norm float64
+	var hitCount int
+	for j, hit := range hits {
+		if hit {
+			norm += math.Pow(math.Abs(ranked[j]), p)
+			hitCount++
+		}
+	}
+	miss := 1 / float64(len(ranked)-hitCount)
+
+	var sum, es float64
+	for j, hit := range hits {
+		switch {
+		case !hit:
+			sum -= miss
+		case norm > 0:
+			sum += math.Pow(math.Abs(ranked[j]), p) / norm
+		default:
+			sum += 1 / float64(hitCount)
+		}
+		if math.Abs(sum) > math.Abs(es) {
+			es = sum
+		}
+	}
+
+	return es
+}
+
+// GSEA is a preranked gene set enrichment analysis of the Shapley values. The null distribution of every set
+// is the enrichment score of random gene sets of the same size. The score is normalized by the mean null score
+// of the same sign, and the FDR compares the tail of all normalized null scores with the tail of the observed ones.
+// Sets with no genes in the ranking, or with all of them, are skipped.
+func GSEA(sValues map[string]float64, sets []GeneSet, perms int, p float64, rng *rand.Rand) []Enrichment {
+	genes := make([]string, 0, len(sValues))
+	for gene := range sValues {
+		genes = append(genes, gene)
+	}
+	sort.Slice(genes, func(i, j int) bool {
+		if sValues[genes[i]] != sValues[genes[j]] {
+			return sValues[genes[i]] > sValues[genes[j]]
+		}
+		return genes[i] < genes[j]
+	})
+	n := len(genes)
+	ranked := make([]float64, n)
+	position := make(map[string]int, n)
+	for j, gene := range genes {
+		ranked[j] = sValues[gene]
+		position[gene] = j
+	}
+
+	var results []Enrichment
+	var nullNES [][]float64
+	hits := make([]bool, n)
+	for _, set := range sets {
+		for j := range hits {
+			hits[j] = false
+		}
+		size := 0
+		for _, gene := range set.Genes {
+			if j, ok := position[gene]; ok && !hits[j] {
+				hits[j] = true
+				size++
+			}
+		}
+		if size == 0 || size == n {
+			continue
+		}
+		es := enrichmentScore(ranked, hits, p)
+
+		null := make([]float64, perms)
+		for b := range null {
+			for j := range hits {
+				hits[j] = false
+			}
+			for _, j := range rng.Perm(n)[:size] {
+				hits[j] = true
+			}
+			null[b] = enrichmentScore(ranked, hits, p)
+		}
+
+		var posSum, negSum float64
+		var posCount, negCount, exceed int
+		for _, x := range null {
+			if x >= 0 {
+				posSum += x
+				posCount++
+				if es >= 0 && x >= es {
+					exceed++
+				}
+			} else {
+				negSum += x
+				negCount++
+				if es < 0 && x <= es {
+					exceed++
+				}
+			}
+		}
+		posMean, negMean := 1.0, 1.0
+		if posCount > 0 {
+			posMean = posSum / float64(posCount)
+		}
+		if negCount > 0 {
+			negMean = -negSum / float64(negCount)
+		}
+		normalize := func(x float64) float64 {
+			if x >= 0 {
+				return x / posMean
+			}
+			return x / negMean
+		}
+
+		r := Enrichment{Name: set.Name, Size: size, ES: es, NES: normalize(es)}
+		switch {
+		case es >= 0 && posCount > 0:
+			r.PValue = float64(exceed) / float64(posCount)
+		case es < 0 && negCount > 0:
+			r.PValue = float64(exceed) / float64(negCount)
+		default:
+			r.PValue = 1
+		}
+		for b, x := range null {
+			null[b] = normalize(x)
+		}
+		results = append(results, r)
+		nullNES = append(nullNES, null)
+	}
+
+	for i := range results {
+		results[i].FDR = fdr(results[i].NES, results, nullNES)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].NES > results[j].NES })
+
+	return results
+}
+
+// fdr is the false discovery rate of a normalized score: the share of null scores of the same sign at least as extreme
+// over the share of observed scores of the same sign at least as extreme.
+func fdr(nes float64, results []Enrichment, nullNES [][]float64) float64 {
+	extreme := func(x float64) bool {
+		if nes >= 0 {
+			return x >= nes
+		}
+		return x <= nes
+	}
+	sameSign := func(x float64) bool { return (x >= 0) == (nes >= 0) }
+
+	var nullSame, nullExtreme, obsSame, obsExtreme int
+	for _, null := range nullNES {
+		for _, x := range null {
+			if sameSign(x) {
+				nullSame++
+				if extreme(x) {
+					nullExtreme++
+				}
+			}
+		}
+	}
+	for _, r := range results {
+		if sameSign(r.NES) {
+			obsSame++
+			if extreme(r.NES) {
+				obsExtreme++
+			}
+		}
+	}
+	if nullSame == 0 {
+		return 1
+	}
+
+	q := (float64(nullExtreme) / float64(nullSame)) / (float64(obsExtreme) / float64(obsSame))
+
+	return math.Min(q, 1)
+}
@@ -0,0 +1,63 @@
+package shapley
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_parseGMT(t *testing.T) {
+	sets, err := ParseGMT(strings.NewReader("UP\thttp://example\tA\tB\t\n\nDOWN\tna\tC\n"))
+	if err != nil {
+		t.Fatalf("ParseGMT() error = %v", err)
+	}
+	if len(sets) != 2 || sets[0].Name != "UP" || len(sets[0].Genes) != 2 || sets[1].Genes[0] != "C" {
+		t.Errorf("ParseGMT() = %+v", sets)
+	}
+	if _, err := ParseGMT(strings.NewReader("UP\tA\n")); err == nil {
+		t.Errorf("ParseGMT() error = nil, want error")
+	}
+}
+
+func Test_enrichmentScore(t *testing.T) {
+	ranked := []float64{4, 3, 2, 1}
+	tests := []struct {
+		name string
+		hits []bool
+		want float64
+	}{
+		{name: "top", hits: []bool{true, false, true, false}, want: 2.0 / 3},
+		{name: "bottom", hits: []bool{false, false, false, true}, want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := enrichmentScore(ranked, tt.hits, 1); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("enrichmentScore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_gsea(t *testing.T) {
+	sValues := make(map[string]float64)
+	for i, gene := range NumberedPlayers("G", 30) {
+		sValues[gene] = float64(30 - i)
+	}
+	sets := []GeneSet{
+		{Name: "TOP", Genes: []string{"G01", "G02", "G03", "G04", "G05"}},
+		{Name: "BOTTOM", Genes: []string{"G26", "G27", "G28", "G29", "G30"}},
+		{Name: "EMPTY", Genes: []string{"X"}},
+	}
+	results := GSEA(sValues, sets, 1000, 1, rand.New(rand.NewSource(1)))
+	if len(results) != 2 {
+		t.Fatalf("GSEA() = %+v, want 2 sets", results)
+	}
+	top, bottom := results[0], results[1]
+	if top.Name != "TOP" || top.ES <= 0 || top.NES <= 1 || top.PValue > 0.01 || top.FDR > 0.05 {
+		t.Errorf("GSEA() TOP = %+v", top)
+	}
+	if bottom.Name != "BOTTOM" || bottom.ES >= 0 || bottom.NES >= -1 || bottom.PValue > 0.01 {
+		t.Errorf("GSEA() BOTTOM = %+v", bottom)
+	}
+}
@@ -0,0 +1,203 @@
+package shapley
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Interval is a closed range [Lo, Hi] guaranteed to contain the exact real value.
+// Every operation rounds its bounds outward by one ulp, which covers the round-to-nearest error of float64.
+type Interval struct {
+	Lo, Hi float64
+}
+
+func down(x float64) float64 { return math.Nextafter(x, math.Inf(-1)) }
+func up(x float64) float64   { return math.Nextafter(x, math.Inf(1)) }
+
+func (a Interval) add(b Interval) Interval {
+	return Interval{Lo: down(a.Lo + b.Lo), Hi: up(a.Hi + b.Hi)}
+}
+
+func (a Interval) sub(b Interval) Interval {
+	return Interval{Lo: down(a.Lo - b.Hi), Hi: up(a.Hi - b.Lo)}
+}
+
+func (a Interval) mul(b Interval) Interval {
+	p := [4]float64{a.Lo * b.Lo, a.Lo * b.Hi, a.Hi * b.Lo, a.Hi * b.Hi}
+	lo, hi := p[0], p[0]
+	for _, x := range p[1:] {
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+
+	return Interval{Lo: down(lo), Hi: up(hi)}
+}
+
+// Mid returns the midpoint of the interval.
+func (a Interval) Mid() float64 {
+	return a.Lo + (a.Hi-a.Lo)/2
+}
+
+// ratInterval returns the tightest float64 interval around r.
+func ratInterval(r *big.Rat) Interval {
+	f, exact := r.Float64()
+	switch {
+	case exact:
+		return Interval{Lo: f, Hi: f}
+	case new(big.Rat).SetFloat64(f).Cmp(r) < 0:
+		return Interval{Lo: f, Hi: up(f)}
+	default:
+		return Interval{Lo: down(f), Hi: f}
+	}
+}
+
+// parseInterval reads a decimal as the tightest interval around it, widened by an optional radius.
+func parseInterval(value, radius string) (Interval, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return Interval{}, fmt.Errorf("failed to convert string to rational, %q", value)
+	}
+	x := ratInterval(r)
+	if radius = strings.TrimSpace(radius); radius == "" {
+		return x, nil
+	}
+
+	rad, err := strconv.ParseFloat(radius, 64)
+	if err != nil || rad < 0 {
+		return Interval{}, fmt.Errorf("invalid uncertainty %q", radius)
+	}
+	return x.add(Interval{Lo: -rad, Hi: rad}), nil
+}
+
+// IntervalResult is the Shapley value of every player as an interval.
+type IntervalResult struct {
+	Values map[string]Interval
+	Sum    Interval
+}
+
+// CheckNormalized fails unless the sum may be one.
+func (r *IntervalResult) CheckNormalized() error {
+	if r.Sum.Hi < 1-epsilon || r.Sum.Lo > 1+epsilon {
+		return fmt.Errorf("sum of Shapley values isn't equal to one, [%v, %v]", r.Sum.Lo, r.Sum.Hi)
+	}
+
+	return nil
+}
+
+// Interval computes the Shapley values of the game of records in interval arithmetic: every value is an interval
+// guaranteed to hold the exact Shapley value of any game whose dividends lie within the input uncertainty.
+func (s Solver) Interval(records [][]string) (*IntervalResult, error) {
+	if err := checkRecords(records); err != nil {
+		return nil, err
+	}
+	players, bitset, dividends, err := handleInterval(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to handle data, %w", err)
+	}
+
+	sValues, checkSum := shapleyInterval(players, bitset, dividends)
+
+	return &IntervalResult{Values: sValues, Sum: checkSum}, nil
+}
+
+// handleInterval reads the dividends of handle as intervals, dense by coalition.
+// An optional third column of a row is the radius of its uncertainty.
+func handleInterval(records [][]string) (players []string, bitset []Coalition, dividends []Interval, err error) {
+	players, index, err := rowPlayers(records)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bitset = make([]Coalition, len(players))
+	for i := range bitset {
+		bitset[i] = Singleton(i)
+	}
+
+	dividends = make([]Interval, 1<<len(players))
+	for _, rec := range records {
+		coalition, err := parseCoalition(rec[0], index)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		var radius string
+		if len(rec) > 2 {
+			radius = rec[2]
+		}
+		if dividends[coalition], err = parseInterval(rec[1], radius); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	return players, bitset, dividends, nil
+}
+
+// shapleyInterval is shapley in interval arithmetic. The worths are summed from the dividends by the zeta transform,
+// the weights are the exact ratios rounded outward and the marginal contributions are summed per coalition size first.
+//
+// v(S U {i}) and v(S) share the uncertainty of the dividends of S, which interval subtraction can't cancel,
+// so the result is intersected with φ_i = Σ_{S∋i} d_S/|S|, where every dividend appears once.
+func shapleyInterval(players []string, bitset []Coalition, dividends []Interval) (map[string]Interval, Interval) {
+	n := len(players)
+	weights := make([]Interval, n)
+	inverses := make([]Interval, n+1)
+	for k, w := range ratWeights(n) {
+		weights[k] = ratInterval(w)
+		inverses[k+1] = ratInterval(big.NewRat(1, int64(k+1)))
+	}
+
+	worths := make([]Interval, len(dividends))
+	copy(worths, dividends)
+	for _, bs := range bitset {
+		for S := range worths {
+			if Coalition(S)&bs != 0 {
+				worths[S] = worths[S].add(worths[Coalition(S)&^bs])
+			}
+		}
+	}
+
+	vector := make([]Interval, n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i, bs := range bitset {
+		go func(i int, bs Coalition) {
+			defer wg.Done()
+
+			bySize := make([]Interval, n)
+			for S := range worths {
+				if Coalition(S)&bs != 0 {
+					continue
+				}
+				k := Coalition(S).Size()
+				// Marginal contribution = v(S U {i})-v(S)
+				bySize[k] = bySize[k].add(worths[Coalition(S)|bs].sub(worths[S]))
+			}
+
+			var value Interval
+			for k, sum := range bySize {
+				// Weight = |S|!(n-|S|-1)!/n!
+				value = value.add(sum.mul(weights[k]))
+			}
+
+			var share Interval
+			for S, d := range dividends {
+				if Coalition(S)&bs != 0 {
+					share = share.add(d.mul(inverses[Coalition(S).Size()]))
+				}
+			}
+			vector[i] = Interval{Lo: math.Max(value.Lo, share.Lo), Hi: math.Min(value.Hi, share.Hi)}
+		}(i, bs)
+	}
+	wg.Wait()
+
+	var vSum Interval
+	sValues := make(map[string]Interval, n)
+	for i, value := range vector {
+		vSum = vSum.add(value)
+		sValues[players[i]] = value
+	}
+
+	return sValues, vSum
+}
@@ -0,0 +1,65 @@
+package shapley
+
+import (
+	"math/big"
+	"testing"
+)
+
+func Test_shapleyInterval(t *testing.T) {
+	tests := []struct {
+		name    string
+		records [][]string
+		radius  float64
+	}{
+		{name: "simple", records: mockRecords()},
+		{name: "uncertain", records: [][]string{{"Google", "0.18", "0.01"}, {"Meta", "0.04"}, {"Microsoft", "0.08"}, {"Meta Google", "0.1"},
+			{"Microsoft Google", "0.26"}, {"Meta Microsoft", "0.07"}, {"Meta Microsoft Google", "0.27"}}, radius: 0.01},
+	}
+	want := map[string]*big.Rat{"Google": big.NewRat(9, 20), "Meta": big.NewRat(43, 200), "Microsoft": big.NewRat(67, 200)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players, bitset, dividends, err := handleInterval(tt.records)
+			if err != nil {
+				t.Fatalf("handleInterval() error = %v", err)
+			}
+			got, _ := shapleyInterval(players, bitset, dividends)
+			for key, wantValue := range want {
+				v := got[key]
+				lo, hi := new(big.Rat).SetFloat64(v.Lo), new(big.Rat).SetFloat64(v.Hi)
+				if lo.Cmp(wantValue) > 0 || hi.Cmp(wantValue) < 0 {
+					t.Errorf("%s: [%v, %v] doesn't contain %s", key, v.Lo, v.Hi, wantValue.RatString())
+				}
+				if width := v.Hi - v.Lo; (key == "Google" && width < 2*tt.radius) || width > 2*tt.radius+1e-14 {
+					t.Errorf("%s: width %g, uncertainty %g", key, width, tt.radius)
+				}
+				if width := v.Hi - v.Lo; key != "Google" && width > 1e-14 {
+					t.Errorf("%s: width %g, the uncertainty is Google's alone", key, width)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseInterval(t *testing.T) {
+	tests := []struct {
+		value, radius string
+		exact         bool
+		wantErr       bool
+	}{
+		{value: "0.5", exact: true},
+		{value: "0.1"},
+		{value: "0.1", radius: "-1", wantErr: true},
+		{value: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseInterval(tt.value, tt.radius)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseInterval() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.Lo == got.Hi) != tt.exact {
+				t.Errorf("parseInterval() = %v, exact %v", got, tt.exact)
+			}
+		})
+	}
+}
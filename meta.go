@@ -0,0 +1,217 @@
+package shapley
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+)
+
+// Cohort is the Shapley vector of one dataset. Stderrs is nil when its rows have no standard error column.
+type Cohort struct {
+	Values, Stderrs map[string]float64
+}
+
+// MetaResult is the pooled Shapley value of a gene over the cohorts that contain it.
+type MetaResult struct {
+	Pooled, Stderr float64 // Stderr is NaN when a cohort has no standard errors
+	Cohorts        int
+	Q, I2          float64 // Cochran's Q and I², NaN without standard errors
+	Borda          float64 // mean normalized rank, 1/m for the top of a list of m genes and 1 for its bottom
+	RRA            float64 // robust rank aggregation score, Bonferroni corrected
+}
+
+// LoadCohort computes the Shapley values of a data file and, when every row has a third column,
+// their standard errors by Covariance.
+func LoadCohort(path string) (Cohort, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Cohort{}, fmt.Errorf("failed to open csv file, %w", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[WARN] closing file: %v", err)
+		}
+	}()
+
+	records, err := ReadRecords(f)
+	if err != nil {
+		return Cohort{}, err
+	}
+	g, err := ParseGame(records)
+	if err != nil {
+		return Cohort{}, err
+	}
+	res, err := Shapley(g)
+	if err != nil {
+		return Cohort{}, err
+	}
+	if err := res.CheckEfficient(g); err != nil {
+		return Cohort{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	c := Cohort{Values: res.Values}
+	for _, rec := range records {
+		if len(rec) < 3 {
+			return c, nil
+		}
+	}
+	covs, err := RowStderrs(g, records)
+	if err != nil {
+		return Cohort{}, err
+	}
+	cov := Covariance(g, covs)
+	c.Stderrs = make(map[string]float64, len(g.Players))
+	for i, player := range g.Players {
+		c.Stderrs[player] = math.Sqrt(cov[i][i])
+	}
+
+	return c, nil
+}
+
+// MetaAnalysis pools the Shapley values of every gene over the cohorts that contain it.
+//
+// With method "ivw" the weights are the inverse variances 1/se², with method "size" the sample sizes.
+// Heterogeneity is Cochran's Q = Σ (φ_c - φ_ivw)²/se_c² with I² = max(0, (Q - df)/Q), df = cohorts - 1,
+// so it needs standard errors whatever the pooling. The orderings are aggregated by the Borda mean of
+// normalized ranks and by the ρ score of Kolde et al. (2012), where a gene missing from a cohort has rank 1.
+func MetaAnalysis(cohorts []Cohort, sizes []float64, method string) (map[string]MetaResult, error) {
+	switch method {
+	case "ivw":
+		for i, c := range cohorts {
+			if c.Stderrs == nil {
+				return nil, fmt.Errorf("cohort %d has no standard errors for inverse-variance weights", i+1)
+			}
+		}
+	case "size":
+		if len(sizes) != len(cohorts) {
+			return nil, fmt.Errorf("want %d sample sizes, %d", len(cohorts), len(sizes))
+		}
+	default:
+		return nil, fmt.Errorf("unknown pooling method %q", method)
+	}
+
+	ranks := make([]map[string]float64, len(cohorts))
+	for i, c := range cohorts {
+		ranks[i] = normalizedRanks(c.Values)
+	}
+
+	results := make(map[string]MetaResult)
+	for _, c := range cohorts {
+		for gene := range c.Values {
+			if _, ok := results[gene]; ok {
+				continue
+			}
+
+			var values, ses, weights, geneRanks []float64
+			hasSE := true
+			for i, other := range cohorts {
+				r, ok := ranks[i][gene]
+				if !ok {
+					geneRanks = append(geneRanks, 1)
+					continue
+				}
+				geneRanks = append(geneRanks, r)
+				values = append(values, other.Values[gene])
+				se := math.NaN()
+				if other.Stderrs != nil {
+					se = other.Stderrs[gene]
+				} else {
+					hasSE = false
+				}
+				ses = append(ses, se)
+				if method == "ivw" {
+					weights = append(weights, 1/(se*se))
+				} else {
+					weights = append(weights, sizes[i])
+				}
+			}
+
+			res := MetaResult{Cohorts: len(values), Stderr: math.NaN(), Q: math.NaN(), I2: math.NaN(), RRA: rhoScore(geneRanks)}
+			var wSum, wvSum, wseSum float64
+			for k, w := range weights {
+				wSum += w
+				wvSum += w * values[k]
+				wseSum += w * w * ses[k] * ses[k]
+			}
+			res.Pooled = wvSum / wSum
+			if hasSE {
+				res.Stderr = math.Sqrt(wseSum) / wSum
+				res.Q, res.I2 = heterogeneity(values, ses)
+			}
+			for i := range cohorts {
+				if r, ok := ranks[i][gene]; ok {
+					res.Borda += r / float64(res.Cohorts)
+				}
+			}
+			results[gene] = res
+		}
+	}
+
+	return results, nil
+}
+
+// heterogeneity returns Cochran's Q and I² of values with standard errors ses.
+func heterogeneity(values, ses []float64) (q, i2 float64) {
+	if len(values) < 2 {
+		return 0, 0
+	}
+	var wSum, wvSum float64
+	for k, se := range ses {
+		wSum += 1 / (se * se)
+		wvSum += values[k] / (se * se)
+	}
+	pooled := wvSum / wSum
+	for k, se := range ses {
+		q += (values[k] - pooled) * (values[k] - pooled) / (se * se)
+	}
+	if df := float64(len(values) - 1); q > df {
+		i2 = (q - df) / q
+	}
+
+	return q, i2
+}
+
+// normalizedRanks returns the rank of every gene in descending order of values divided by the number of genes.
+func normalizedRanks(values map[string]float64) map[string]float64 {
+	genes := make([]string, 0, len(values))
+	for gene := range values {
+		genes = append(genes, gene)
+	}
+	sort.Slice(genes, func(i, j int) bool {
+		if values[genes[i]] != values[genes[j]] {
+			return values[genes[i]] > values[genes[j]]
+		}
+		return genes[i] < genes[j]
+	})
+
+	ranks := make(map[string]float64, len(genes))
+	for j, gene := range genes {
+		ranks[gene] = float64(j+1) / float64(len(genes))
+	}
+
+	return ranks
+}
+
+// rhoScore is the RRA score of normalized ranks: ρ = min_j P(Beta(j, k-j+1) ≤ r_(j)) over the sorted ranks,
+// where the beta probability is P(Binomial(k, r_(j)) ≥ j), multiplied by k for the multiple comparison.
+func rhoScore(ranks []float64) float64 {
+	sorted := make([]float64, len(ranks))
+	copy(sorted, ranks)
+	sort.Float64s(sorted)
+
+	k := len(sorted)
+	rho := 1.0
+	for j, x := range sorted {
+		var tail float64
+		for l := j + 1; l <= k; l++ {
+			tail += binomial(k, l) * math.Pow(x, float64(l)) * math.Pow(1-x, float64(k-l))
+		}
+		if tail < rho {
+			rho = tail
+		}
+	}
+
+	return math.Min(rho*float64(k), 1)
+}
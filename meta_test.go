@@ -0,0 +1,62 @@
+package shapley
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_heterogeneity(t *testing.T) {
+	q, i2 := heterogeneity([]float64{1, 3}, []float64{1, 1})
+	if math.Abs(q-2) > 1e-9 || math.Abs(i2-0.5) > 1e-9 {
+		t.Errorf("heterogeneity() = %v, %v, want 2, 0.5", q, i2)
+	}
+	if _, i2 := heterogeneity([]float64{1, 1.5}, []float64{1, 1}); i2 != 0 {
+		t.Errorf("heterogeneity() I2 = %v, want 0", i2)
+	}
+}
+
+func Test_rhoScore(t *testing.T) {
+	tests := []struct {
+		ranks []float64
+		want  float64
+	}{
+		{ranks: []float64{0.5}, want: 0.5},
+		{ranks: []float64{0.2, 0.1}, want: 0.08},
+		{ranks: []float64{1, 1}, want: 1},
+	}
+	for _, tt := range tests {
+		if got := rhoScore(tt.ranks); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("rhoScore(%v) = %v, want %v", tt.ranks, got, tt.want)
+		}
+	}
+}
+
+func Test_metaAnalysis(t *testing.T) {
+	cohorts := []Cohort{
+		{Values: map[string]float64{"A": 0.6, "B": 0.4}, Stderrs: map[string]float64{"A": 0.1, "B": 0.1}},
+		{Values: map[string]float64{"A": 0.5, "C": 0.5}, Stderrs: map[string]float64{"A": 0.2, "C": 0.1}},
+	}
+	results, err := MetaAnalysis(cohorts, nil, "ivw")
+	if err != nil {
+		t.Fatalf("MetaAnalysis() error = %v", err)
+	}
+	a := results["A"]
+	if math.Abs(a.Pooled-0.58) > 1e-9 || math.Abs(a.Stderr-1/math.Sqrt(125)) > 1e-9 || a.Cohorts != 2 || math.Abs(a.Borda-0.5) > 1e-9 {
+		t.Errorf("MetaAnalysis() A = %+v", a)
+	}
+	if b := results["B"]; math.Abs(b.Pooled-0.4) > 1e-9 || b.Cohorts != 1 || b.Borda != 1 || b.Q != 0 {
+		t.Errorf("MetaAnalysis() B = %+v", b)
+	}
+
+	cohorts[1].Stderrs = nil
+	if _, err := MetaAnalysis(cohorts, nil, "ivw"); err == nil {
+		t.Errorf("MetaAnalysis() error = nil, want error")
+	}
+	results, err = MetaAnalysis(cohorts, []float64{10, 30}, "size")
+	if err != nil {
+		t.Fatalf("MetaAnalysis() error = %v", err)
+	}
+	if a := results["A"]; math.Abs(a.Pooled-0.525) > 1e-9 || !math.IsNaN(a.Q) {
+		t.Errorf("MetaAnalysis() A = %+v", a)
+	}
+}
@@ -0,0 +1,218 @@
+package shapley
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// ValueFunc returns the worth of coalition S, bit i of S is the i-th player of the Oracle.
+// It may be called concurrently for different coalitions.
+type ValueFunc func(ctx context.Context, S Coalition) (float64, error)
+
+// Incremental is a value function that keeps a current coalition, empty at first, and changes it one player at a time.
+// GrayGame visits the coalitions in Gray code order, so every coalition costs one Add or Remove and one Value.
+type Incremental interface {
+	Add(i int) error
+	Remove(i int) error
+	Value() (float64, error)
+}
+
+// Oracle evaluates the worths of a game on demand and memoizes them.
+type Oracle struct {
+	memo        map[Coalition]*memoEntry
+	value       ValueFunc
+	sem         chan struct{}
+	Players     []string
+	evaluations int
+	mu          sync.Mutex
+}
+
+// memoEntry is the worth of a coalition, ready once done is closed. Concurrent lookups of the same coalition
+// wait for the one evaluation instead of starting their own.
+type memoEntry struct {
+	done  chan struct{}
+	err   error
+	worth float64
+}
+
+// NewOracle returns an oracle of value over players with at most concurrency evaluations at a time,
+// GOMAXPROCS when concurrency isn't positive.
+func NewOracle(players []string, value ValueFunc, concurrency int) *Oracle {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+
+	return &Oracle{
+		Players: players,
+		value:   value,
+		memo:    make(map[Coalition]*memoEntry, 1<<len(players)),
+		sem:     make(chan struct{}, concurrency),
+	}
+}
+
+// Evaluations returns how many times the value function has been called.
+func (o *Oracle) Evaluations() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.evaluations
+}
+
+// Worth returns the worth of S, evaluating it at most once. A failed evaluation isn't memoized.
+func (o *Oracle) Worth(ctx context.Context, S Coalition) (float64, error) {
+	if S == 0 {
+		return 0, nil
+	}
+
+	o.mu.Lock()
+	e, ok := o.memo[S]
+	if !ok {
+		e = &memoEntry{done: make(chan struct{})}
+		o.memo[S] = e
+	}
+	o.mu.Unlock()
+	if ok {
+		select {
+		case <-e.done:
+			return e.worth, e.err
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	select {
+	case o.sem <- struct{}{}:
+		o.mu.Lock()
+		o.evaluations++
+		o.mu.Unlock()
+		e.worth, e.err = o.value(ctx, S)
+		<-o.sem
+	case <-ctx.Done():
+		e.err = ctx.Err()
+	}
+	if e.err != nil {
+		o.mu.Lock()
+		delete(o.memo, S)
+		o.mu.Unlock()
+	}
+	close(e.done)
+
+	return e.worth, e.err
+}
+
+// Game evaluates every coalition and returns the game of the worths. Every worker evaluates a contiguous range
+// of the Gray code order, so its consecutive coalitions differ in one player, which keeps the caches of a value
+// function warm.
+func (o *Oracle) Game(ctx context.Context) (*Game, error) {
+	if n := len(o.Players); n == 0 || n > MaxPlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g := NewGame(o.Players)
+	N := int(g.Grand())
+	worths := make([]float64, N+1)
+	errs := make([]error, N+1)
+
+	workers := cap(o.sem)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func(lo, hi int) {
+			defer wg.Done()
+			for k := lo; k <= hi && ctx.Err() == nil; k++ {
+				S := gray(k)
+				worth, err := o.Worth(ctx, S)
+				if err != nil {
+					errs[S] = fmt.Errorf("coalition %s: %w", g.Name(S), err)
+					cancel()
+					return
+				}
+				worths[S] = worth
+			}
+		}(1+w*N/workers, (w+1)*N/workers)
+	}
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for S := 1; S <= N; S++ {
+		g.Worths[Coalition(S)] = worths[S]
+	}
+
+	return g, nil
+}
+
+// ShapleyOracle returns the Shapley values of the game of o.
+func (s Solver) ShapleyOracle(ctx context.Context, o *Oracle) (*Result, error) {
+	g, err := o.Game(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ShapleyContext(ctx, g)
+}
+
+// GrayGame evaluates inc on every coalition of players in Gray code order: coalition k is k ^ k>>1,
+// and it differs from coalition k-1 in the player of the lowest set bit of k.
+func GrayGame(ctx context.Context, players []string, inc Incremental) (*Game, error) {
+	if n := len(players); n == 0 || n > MaxPlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+
+	g := NewGame(players)
+	var S Coalition
+	for k := 1; k <= int(g.Grand()); k++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		i := bits.TrailingZeros(uint(k))
+		var err error
+		if !S.Has(i) {
+			err = inc.Add(i)
+		} else {
+			err = inc.Remove(i)
+		}
+		S ^= 1 << i
+		if err != nil {
+			return nil, fmt.Errorf("coalition %s: %w", g.Name(S), err)
+		}
+		if g.Worths[S], err = inc.Value(); err != nil {
+			return nil, fmt.Errorf("coalition %s: %w", g.Name(S), err)
+		}
+	}
+
+	return g, nil
+}
+
+// gray is the k-th coalition of the binary reflected Gray code.
+func gray(k int) Coalition {
+	return Coalition(k ^ k>>1)
+}
+
+// firstError returns the first error of errs that isn't a cancellation caused by it, or else the first error.
+func firstError(errs []error) error {
+	var canceled error
+	for _, err := range errs {
+		switch {
+		case err == nil:
+		case errors.Is(err, context.Canceled):
+			if canceled == nil {
+				canceled = err
+			}
+		default:
+			return err
+		}
+	}
+
+	return canceled
+}
@@ -0,0 +1,126 @@
+package shapley
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sumSquared is the incremental value function v(S) = (Σ_{i∈S} w_i)².
+type sumSquared struct {
+	weights []float64
+	sum     float64
+	steps   int
+}
+
+func (s *sumSquared) Add(i int) error    { s.sum += s.weights[i]; s.steps++; return nil }
+func (s *sumSquared) Remove(i int) error { s.sum -= s.weights[i]; s.steps++; return nil }
+func (s *sumSquared) Value() (float64, error) {
+	return s.sum * s.sum, nil
+}
+
+func Test_Oracle(t *testing.T) {
+	weights := []float64{1, 2, 3, 4, 5}
+	players := NumberedPlayers("P", len(weights))
+	var running, peak int32
+	var (
+		mu    sync.Mutex
+		calls []Coalition
+	)
+	value := func(ctx context.Context, S Coalition) (float64, error) {
+		mu.Lock()
+		calls = append(calls, S)
+		mu.Unlock()
+		if r := atomic.AddInt32(&running, 1); r > atomic.LoadInt32(&peak) {
+			atomic.StoreInt32(&peak, r)
+		}
+		defer atomic.AddInt32(&running, -1)
+		time.Sleep(100 * time.Microsecond)
+		var sum float64
+		for i, w := range weights {
+			if S.Has(i) {
+				sum += w
+			}
+		}
+		return sum * sum, nil
+	}
+
+	o := NewOracle(players, value, 2)
+	res, err := Solver{}.ShapleyOracle(context.Background(), o)
+	if err != nil {
+		t.Fatalf("ShapleyOracle() error = %v", err)
+	}
+	// φ_i = w_i·Σw for v = (Σw)²
+	for i, w := range weights {
+		if got, want := res.Values[players[i]], w*15; math.Abs(got-want) > 1e-9 {
+			t.Errorf("ShapleyOracle()[%s] = %v, want %v", players[i], got, want)
+		}
+	}
+	if peak > 2 {
+		t.Errorf("ShapleyOracle() ran %d evaluations at once, want at most 2", peak)
+	}
+	// the 2 workers walk the Gray codes 1 to 15 and 16 to 31
+	index := make(map[Coalition]int, len(calls))
+	for k := 1; k < 32; k++ {
+		index[gray(k)] = k
+	}
+	var last [2]Coalition
+	for _, S := range calls {
+		var w int
+		if index[S] > 15 {
+			w = 1
+		}
+		if last[w] != 0 && (S^last[w]).Size() != 1 {
+			t.Errorf("ShapleyOracle() evaluated %b after %b in a worker", S, last[w])
+		}
+		last[w] = S
+	}
+	if got, err := o.Worth(context.Background(), 0b11); err != nil || got != 9 {
+		t.Errorf("Worth() = %v, %v, want 9", got, err)
+	}
+	if got := o.Evaluations(); got != 31 {
+		t.Errorf("Evaluations() = %d, want 31", got)
+	}
+
+	inc := &sumSquared{weights: weights}
+	g, err := GrayGame(context.Background(), players, inc)
+	if err != nil {
+		t.Fatalf("GrayGame() error = %v", err)
+	}
+	if inc.steps != 31 {
+		t.Errorf("GrayGame() took %d steps, want 31", inc.steps)
+	}
+	want, _ := o.Game(context.Background())
+	for S, worth := range want.Worths {
+		if g.Worths[S] != worth {
+			t.Errorf("GrayGame()[%b] = %v, want %v", S, g.Worths[S], worth)
+		}
+	}
+
+	failing := NewOracle(players, func(ctx context.Context, S Coalition) (float64, error) {
+		if S == 0b10100 {
+			return 0, errors.New("model failed")
+		}
+		return 0, nil
+	}, 0)
+	if _, err := failing.Game(context.Background()); err == nil {
+		t.Errorf("Game() error = nil, want error")
+	}
+}
+
+func Test_gray(t *testing.T) {
+	prev := Coalition(0)
+	seen := make(map[Coalition]bool)
+	for k := 1; k < 1<<6; k++ {
+		S := gray(k)
+		if d := (S ^ prev).Size(); d != 1 || seen[S] {
+			t.Fatalf("gray(%d) = %b after %b", k, S, prev)
+		}
+		seen[S] = true
+		prev = S
+	}
+}
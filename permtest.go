@@ -0,0 +1,127 @@
+package shapley
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// PermResult is the significance of the Shapley value of a player against a permutation null.
+type PermResult struct {
+	Value  float64
+	PValue float64 // one-sided empirical p-value of a value at least as large
+	QValue float64 // Benjamini–Hochberg adjusted p-value
+}
+
+// shapleyFromDividends returns φ_i = Σ_{S∋i} d_S/|S|, d is indexed by coalition.
+func shapleyFromDividends(bitset []Coalition, d []float64) []float64 {
+	vector := make([]float64, len(bitset))
+	for S, dividend := range d {
+		if S == 0 || dividend == 0 {
+			continue
+		}
+		share := dividend / float64(Coalition(S).Size())
+		for i, bs := range bitset {
+			if Coalition(S)&bs != 0 {
+				vector[i] += share
+			}
+		}
+	}
+
+	return vector
+}
+
+// PermutationTest compares the Shapley values of g with those of permuted games.
+//
+// With mode "values" the dividends are shuffled over all non-empty coalitions, which keeps v(N).
+// With mode "labels" the players are relabelled by an independent random permutation for every coalition size,
+// which keeps the dividends of every size but breaks which players carry them. The share of player j in the dividends
+// of size k is then computed once, and a permuted value is Σ_k share_k(π_k⁻¹(i)).
+func PermutationTest(g *Game, mode string, perms int, rng *rand.Rand) ([]PermResult, error) {
+	n := len(g.Players)
+	d := Dividends(g)
+	bitset := g.bits()
+	observed := shapleyFromDividends(bitset, d)
+
+	var permuted func() []float64
+	switch mode {
+	case "values":
+		shuffled := make([]float64, len(d))
+		copy(shuffled, d)
+		permuted = func() []float64 {
+			rng.Shuffle(len(shuffled)-1, func(a, b int) {
+				shuffled[a+1], shuffled[b+1] = shuffled[b+1], shuffled[a+1]
+			})
+			return shapleyFromDividends(bitset, shuffled)
+		}
+	case "labels":
+		shares := make([][]float64, n+1)
+		for k := range shares {
+			shares[k] = make([]float64, n)
+		}
+		for S, dividend := range d {
+			k := Coalition(S).Size()
+			for i, bs := range bitset {
+				if Coalition(S)&bs != 0 {
+					shares[k][i] += dividend / float64(k)
+				}
+			}
+		}
+		vector := make([]float64, n)
+		permuted = func() []float64 {
+			for i := range vector {
+				vector[i] = 0
+			}
+			for k := 1; k <= n; k++ {
+				for j, i := range rng.Perm(n) {
+					vector[i] += shares[k][j]
+				}
+			}
+			return vector
+		}
+	default:
+		return nil, fmt.Errorf("unknown permutation mode %q", mode)
+	}
+
+	exceed := make([]int, n)
+	for b := 0; b < perms; b++ {
+		for i, value := range permuted() {
+			if value >= observed[i]-epsilon {
+				exceed[i]++
+			}
+		}
+	}
+
+	results := make([]PermResult, n)
+	pValues := make([]float64, n)
+	for i := range results {
+		pValues[i] = float64(1+exceed[i]) / float64(1+perms)
+		results[i] = PermResult{Value: observed[i], PValue: pValues[i]}
+	}
+	for i, q := range BenjaminiHochberg(pValues) {
+		results[i].QValue = q
+	}
+
+	return results, nil
+}
+
+// BenjaminiHochberg returns the step-up adjusted p-values q_(i) = min_{j≥i} p_(j)·m/j that control the false discovery rate.
+func BenjaminiHochberg(pValues []float64) []float64 {
+	m := len(pValues)
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return pValues[order[a]] < pValues[order[b]] })
+
+	qValues := make([]float64, m)
+	q := 1.0
+	for r := m - 1; r >= 0; r-- {
+		if adjusted := pValues[order[r]] * float64(m) / float64(r+1); adjusted < q {
+			q = adjusted
+		}
+		qValues[order[r]] = q
+	}
+
+	return qValues
+}
@@ -0,0 +1,74 @@
+package shapley
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_shapleyFromDividends(t *testing.T) {
+	g := mockGame()
+	vector := shapleyFromDividends(g.bits(), Dividends(g))
+	sValues := make(map[string]float64, len(vector))
+	for i, value := range vector {
+		sValues[g.Players[i]] = value
+	}
+	assertValues(t, sValues, map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335})
+}
+
+func Test_benjaminiHochberg(t *testing.T) {
+	pValues := []float64{0.01, 0.04, 0.03, 0.2}
+	want := []float64{0.04, 0.04 * 4 / 3, 0.04 * 4 / 3, 0.2}
+	for i, q := range BenjaminiHochberg(pValues) {
+		if math.Abs(q-want[i]) > 1e-9 {
+			t.Errorf("BenjaminiHochberg()[%d] = %v, want %v", i, q, want[i])
+		}
+	}
+}
+
+func Test_permutationTest(t *testing.T) {
+	// Only the grand coalition has a dividend: every permutation gives the same values.
+	g := NewGame([]string{"A", "B", "C"})
+	g.Worths[g.Grand()] = 1
+	for _, mode := range []string{"values", "labels"} {
+		results, err := PermutationTest(g, mode, 99, rand.New(rand.NewSource(1)))
+		if err != nil {
+			t.Fatalf("PermutationTest(%s) error = %v", mode, err)
+		}
+		for i, r := range results {
+			if mode == "labels" && r.PValue != 1 {
+				t.Errorf("PermutationTest(%s)[%d].pValue = %v, want 1", mode, i, r.PValue)
+			}
+			if r.PValue < 0.01 || r.QValue < r.PValue {
+				t.Errorf("PermutationTest(%s)[%d] = %+v", mode, i, r)
+			}
+		}
+	}
+
+	// A singleton carries the whole worth. Relabelling moves its dividend to any of the 8 players,
+	// so the exact p-value is 1/8 and it can't be significant. Shuffling the dividends over
+	// the 255 coalitions puts it back on {P1} with probability 1/255, which is significant.
+	g = NewGame(NumberedPlayers("P", 8))
+	for S := Coalition(1); S <= g.Grand(); S++ {
+		if S&g.bits()[0] != 0 {
+			g.Worths[S] = 1
+		}
+	}
+	results, err := PermutationTest(g, "labels", 999, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("PermutationTest() error = %v", err)
+	}
+	if math.Abs(results[0].PValue-0.125) > 0.05 || results[1].PValue != 1 {
+		t.Errorf("PermutationTest(labels) = %+v", results)
+	}
+	results, err = PermutationTest(g, "values", 999, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("PermutationTest() error = %v", err)
+	}
+	if results[0].PValue > 0.05 || results[0].QValue > 0.05 || results[1].PValue != 1 {
+		t.Errorf("PermutationTest(values) = %+v", results)
+	}
+	if _, err := PermutationTest(g, "genes", 1, rand.New(rand.NewSource(1))); err == nil {
+		t.Errorf("PermutationTest() error = nil, want error")
+	}
+}
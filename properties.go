@@ -0,0 +1,115 @@
+package shapley
+
+// Counterexample is a pair of coalitions for which a property fails.
+type Counterexample struct {
+	A, B Coalition
+}
+
+// Properties of a game; a nil counterexample means the property holds.
+type Properties struct {
+	Monotone      *Counterexample // a ⊂ b and v(a) > v(b)
+	Superadditive *Counterexample // a ∩ b = ∅ and v(a∪b) < v(a) + v(b)
+	Convex        *Counterexample // v(a∪b) + v(a∩b) < v(a) + v(b)
+	Symmetric     [][2]int
+	Null          []int
+	Dummy         []int
+	Singletons    float64 // Σ v({i}), the game is essential when it is less than v(N)
+}
+
+// Essential reports whether v(N) exceeds the sum of the worths of the singletons.
+func (p *Properties) Essential(g *Game) bool {
+	return g.Worths[g.Grand()]-p.Singletons > epsilon
+}
+
+// Analyze checks the properties of g up to epsilon.
+func Analyze(g *Game) *Properties {
+	p := analyzeMarginals(g)
+	p.Superadditive = superadditivity(g)
+
+	return p
+}
+
+// analyzeMarginals checks every property but superadditivity, they only need marginal contributions.
+func analyzeMarginals(g *Game) *Properties {
+	n, N := len(g.Players), g.Grand()
+	p := &Properties{}
+	bitset := g.bits()
+	for _, bs := range bitset {
+		p.Singletons += g.Worths[bs]
+	}
+
+	symmetric := make([]bool, n*n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			symmetric[i*n+j] = true
+		}
+	}
+	null, dummy := make([]bool, n), make([]bool, n)
+	for i := range bitset {
+		null[i], dummy[i] = true, true
+	}
+
+	for s := 0; s <= int(N); s++ {
+		S := Coalition(s)
+		for i, bi := range bitset {
+			if S&bi != 0 {
+				continue
+			}
+			// Marginal contribution = v(S U {i})-v(S)
+			contrib := g.Worths[S|bi] - g.Worths[S]
+			if p.Monotone == nil && contrib < -epsilon {
+				p.Monotone = &Counterexample{S, S | bi}
+			}
+			null[i] = null[i] && !differs(contrib, 0)
+			dummy[i] = dummy[i] && !differs(contrib, g.Worths[bi])
+
+			for j := i + 1; j < n; j++ {
+				bj := bitset[j]
+				if S&bj != 0 {
+					continue
+				}
+				symmetric[i*n+j] = symmetric[i*n+j] && !differs(g.Worths[S|bi], g.Worths[S|bj])
+				// Supermodularity: v(S U {i,j}) - v(S U {j}) >= v(S U {i}) - v(S)
+				if p.Convex == nil && g.Worths[S|bi|bj]-g.Worths[S|bj] < contrib-epsilon {
+					p.Convex = &Counterexample{S | bi, S | bj}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if null[i] {
+			p.Null = append(p.Null, i)
+		}
+		if dummy[i] {
+			p.Dummy = append(p.Dummy, i)
+		}
+		for j := i + 1; j < n; j++ {
+			if symmetric[i*n+j] {
+				p.Symmetric = append(p.Symmetric, [2]int{i, j})
+			}
+		}
+	}
+
+	return p
+}
+
+// superadditivity looks for disjoint coalitions S and T with v(S∪T) < v(S) + v(T), it takes 3^n steps.
+func superadditivity(g *Game) *Counterexample {
+	N := g.Grand()
+	for s := 1; s <= int(N); s++ {
+		S := Coalition(s)
+		rest := N &^ S
+		for T := rest; T != 0; T = (T - 1) & rest {
+			if g.Worths[S|T] < g.Worths[S]+g.Worths[T]-epsilon {
+				return &Counterexample{S, T}
+			}
+		}
+	}
+
+	return nil
+}
+
+func differs(a, b float64) bool {
+	return a-b > epsilon || b-a > epsilon
+}
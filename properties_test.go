@@ -0,0 +1,79 @@
+package shapley
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_analyze(t *testing.T) {
+	tests := []struct {
+		worths        map[Coalition]float64
+		name          string
+		symmetric     [][2]int
+		null, dummy   []int
+		monotone      bool
+		superadditive bool
+		convex        bool
+		essential     bool
+	}{
+		{
+			name:          "simple",
+			worths:        mockWorths(),
+			monotone:      true,
+			superadditive: true,
+			convex:        true,
+			essential:     true,
+		},
+		{
+			// Meta is a null player, Microsoft is a dummy, Google and Meta are not symmetric.
+			name:          "additive",
+			worths:        map[Coalition]float64{0b1: 0.5, 0b11: 0.5, 0b101: 0.7, 0b100: 0.2, 0b110: 0.2, 0b111: 0.7},
+			null:          []int{1},
+			dummy:         []int{0, 1, 2},
+			monotone:      true,
+			superadditive: true,
+			convex:        true,
+		},
+		{
+			// Majority game: any two players win.
+			name:          "majority",
+			worths:        map[Coalition]float64{0b11: 1, 0b101: 1, 0b110: 1, 0b111: 1},
+			symmetric:     [][2]int{{0, 1}, {0, 2}, {1, 2}},
+			monotone:      true,
+			superadditive: true,
+			essential:     true,
+		},
+		{
+			name:      "decreasing",
+			worths:    map[Coalition]float64{0b1: 1, 0b10: 1, 0b100: 1, 0b11: 0.5, 0b101: 0.5, 0b110: 0.5, 0b111: 3},
+			symmetric: [][2]int{{0, 1}, {0, 2}, {1, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &Game{Players: mockPlayers(), Worths: tt.worths}
+			p := Analyze(g)
+			if got := p.Monotone == nil; got != tt.monotone {
+				t.Errorf("monotone = %v, want %v, counterexample %v", got, tt.monotone, p.Monotone)
+			}
+			if got := p.Superadditive == nil; got != tt.superadditive {
+				t.Errorf("superadditive = %v, want %v, counterexample %v", got, tt.superadditive, p.Superadditive)
+			}
+			if got := p.Convex == nil; got != tt.convex {
+				t.Errorf("convex = %v, want %v, counterexample %v", got, tt.convex, p.Convex)
+			}
+			if got := p.Essential(g); got != tt.essential {
+				t.Errorf("essential = %v, want %v", got, tt.essential)
+			}
+			if !reflect.DeepEqual(p.Symmetric, tt.symmetric) {
+				t.Errorf("symmetric = %v, want %v", p.Symmetric, tt.symmetric)
+			}
+			if !reflect.DeepEqual(p.Null, tt.null) {
+				t.Errorf("null = %v, want %v", p.Null, tt.null)
+			}
+			if !reflect.DeepEqual(p.Dummy, tt.dummy) {
+				t.Errorf("dummy = %v, want %v", p.Dummy, tt.dummy)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package shapley
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The coalitions of the rows of a data file are parsed here for every algorithm, so they all read the same game:
+// the players are those of the grand coalition of the last row, a player of a row that isn't one of them or
+// a row without players is an error, and a coalition without a row has a zero dividend.
+
+// rowPlayers returns the sorted players of the grand coalition of the last of records and the index of every player.
+func rowPlayers(records [][]string) ([]string, map[string]int, error) {
+	if len(records) == 0 {
+		return nil, nil, errors.New("no records")
+	}
+	players := strings.Fields(records[len(records)-1][0])
+	if len(players) == 0 {
+		return nil, nil, errors.New("empty coalition")
+	}
+	sort.Strings(players)
+
+	return players, playerIndex(players), nil
+}
+
+// playerIndex maps every player to its index, the bit of its Singleton.
+func playerIndex(players []string) map[string]int {
+	index := make(map[string]int, len(players))
+	for i, player := range players {
+		index[player] = i
+	}
+
+	return index
+}
+
+// parseMembers calls add with the index of every space-separated player of field.
+func parseMembers(field string, index map[string]int, add func(i int)) error {
+	vec := strings.Fields(field)
+	if len(vec) == 0 {
+		return errors.New("empty coalition")
+	}
+	for _, v := range vec {
+		i, ok := index[v]
+		if !ok {
+			return fmt.Errorf("player %s isn't in the grand coalition", v)
+		}
+		add(i)
+	}
+
+	return nil
+}
+
+// parseCoalition returns the coalition of the space-separated players of field.
+func parseCoalition(field string, index map[string]int) (Coalition, error) {
+	var coalition Coalition
+	err := parseMembers(field, index, func(i int) { coalition |= Singleton(i) })
+
+	return coalition, err
+}
+
+// parseBigCoalition is parseCoalition for any number of players.
+func parseBigCoalition(field string, index map[string]int) (BigCoalition, error) {
+	coalition := NewBigCoalition(len(index))
+	err := parseMembers(field, index, coalition.Add)
+
+	return coalition, err
+}
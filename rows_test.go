@@ -0,0 +1,125 @@
+package shapley
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_parseCoalition(t *testing.T) {
+	index := playerIndex(mockPlayers())
+	if got, err := parseCoalition("Microsoft  Google", index); err != nil || got != 0b101 {
+		t.Errorf("parseCoalition() = %b, %v, want 101", got, err)
+	}
+	if _, err := parseCoalition(" ", index); err == nil {
+		t.Errorf("parseCoalition() of an empty coalition error = nil")
+	}
+	if _, err := parseCoalition("Google Apple", index); err == nil || !strings.Contains(err.Error(), "player Apple") {
+		t.Errorf("parseCoalition() of an unknown player error = %v", err)
+	}
+	if got, err := parseBigCoalition("Meta", index); err != nil || !reflect.DeepEqual(got.Members(), []int{1}) {
+		t.Errorf("parseBigCoalition() = %v, %v, want {1}", got.Members(), err)
+	}
+}
+
+// Test_sparseRows runs every algorithm of the rows of a data file on a file without the row of a coalition
+// and on a file with a player that isn't in the grand coalition.
+func Test_sparseRows(t *testing.T) {
+	dir := t.TempDir()
+	algorithms := map[string]func(records [][]string, path string) (map[string]float64, error){
+		"dense": func(records [][]string, _ string) (map[string]float64, error) {
+			g, err := ParseGame(records)
+			if err != nil {
+				return nil, err
+			}
+			res, err := Shapley(g)
+			if err != nil {
+				return nil, err
+			}
+			return res.Values, nil
+		},
+		"dividend": func(records [][]string, _ string) (map[string]float64, error) {
+			res, err := Solver{}.ShapleyDividends(records)
+			if err != nil {
+				return nil, err
+			}
+			return res.Values, nil
+		},
+		"sampling": func(records [][]string, _ string) (map[string]float64, error) {
+			// every order of 3 players is drawn many times, the estimate is close to the values
+			res, err := Solver{}.ShapleySampling(context.Background(), records, 20000, rand.New(rand.NewSource(1)))
+			if err != nil {
+				return nil, err
+			}
+			return res.Values, nil
+		},
+		"table": func(_ [][]string, path string) (map[string]float64, error) {
+			table, err := BuildTable(path, path+".table")
+			if err != nil {
+				return nil, err
+			}
+			defer table.Close()
+			res, err := Solver{}.ShapleyTable(context.Background(), table)
+			if err != nil {
+				return nil, err
+			}
+			return res.Values, nil
+		},
+		"exact": func(records [][]string, _ string) (map[string]float64, error) {
+			res, err := Solver{}.Exact(records)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]float64, len(res.Values))
+			for player, value := range res.Values {
+				values[player], _ = value.Float64()
+			}
+			return values, nil
+		},
+		"interval": func(records [][]string, _ string) (map[string]float64, error) {
+			res, err := Solver{}.Interval(records)
+			if err != nil {
+				return nil, err
+			}
+			values := make(map[string]float64, len(res.Values))
+			for player, value := range res.Values {
+				values[player] = (value.Lo + value.Hi) / 2
+			}
+			return values, nil
+		},
+	}
+
+	// the row of A B is missing, its dividend is zero: φ_i = Σ_{T∋i} d(T)/|T|
+	sparse := "A,.2\nB,.3\nC,.1\nA C,.1\nB C,.1\nA B C,.2"
+	want := map[string]float64{"A": .2 + .1/2 + .2/3, "B": .3 + .1/2 + .2/3, "C": .1 + .1 + .2/3}
+	unknown := "A,.5\nC,.25\nA B,.25"
+	for name, algorithm := range algorithms {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(sparse), 0o644)
+		records, _ := ReadRecords(strings.NewReader(sparse))
+		got, err := algorithm(records, path)
+		if err != nil {
+			t.Fatalf("%s of a sparse file error = %v", name, err)
+		}
+		tol := 1e-12
+		if name == "sampling" {
+			tol = 1e-2
+		}
+		for player, value := range want {
+			if math.Abs(got[player]-value) > tol {
+				t.Errorf("%s of a sparse file: %s = %v, want %v", name, player, got[player], value)
+			}
+		}
+
+		os.WriteFile(path, []byte(unknown), 0o644)
+		records, _ = ReadRecords(strings.NewReader(unknown))
+		if _, err := algorithm(records, path); err == nil || !strings.Contains(err.Error(), "player C") {
+			t.Errorf("%s of an unknown player error = %v", name, err)
+		}
+	}
+}
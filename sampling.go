@@ -0,0 +1,74 @@
+package shapley
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+)
+
+// BigValueFunc returns the worth of a coalition of any number of players, it must not keep S.
+type BigValueFunc func(ctx context.Context, S BigCoalition) (float64, error)
+
+// SampleResult is a Monte Carlo estimate of the Shapley value of every player, indexed like the players.
+type SampleResult struct {
+	Values  []float64
+	Stderrs []float64 // standard errors of the means
+	Perms   int
+}
+
+// SampleShapley estimates the Shapley values of n players by permutation sampling (Castro et al., 2009):
+// the players join in a random order, each is credited with its marginal contribution to those before it,
+// and a value is the mean of its contributions over perms permutations. A permutation costs n evaluations.
+func SampleShapley(ctx context.Context, n int, value BigValueFunc, perms int, rng *rand.Rand) (*SampleResult, error) {
+	if n < 1 || perms < 2 {
+		return nil, errors.New("sampling needs a player and at least 2 permutations")
+	}
+
+	// Welford's running mean and sum of squared deviations
+	mean := make([]float64, n)
+	m2 := make([]float64, n)
+	S := NewBigCoalition(n)
+	for p := 1; p <= perms; p++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		S.Clear()
+		prev, err := value(ctx, S)
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range rng.Perm(n) {
+			S.Add(i)
+			worth, err := value(ctx, S)
+			if err != nil {
+				return nil, err
+			}
+			contrib := worth - prev
+			prev = worth
+
+			delta := contrib - mean[i]
+			mean[i] += delta / float64(p)
+			m2[i] += delta * (contrib - mean[i])
+		}
+	}
+
+	res := &SampleResult{Values: mean, Stderrs: make([]float64, n), Perms: perms}
+	for i := range m2 {
+		res.Stderrs[i] = math.Sqrt(m2[i] / float64(perms-1) / float64(perms))
+	}
+
+	return res, nil
+}
+
+// Result returns the estimates by player as a Result, whose bounds are the standard errors.
+func (r *SampleResult) Result(players []string) *Result {
+	res := &Result{Values: make(map[string]float64, len(players)), Bounds: make(map[string]float64, len(players)), Coverage: 1}
+	for i, player := range players {
+		res.Sum += r.Values[i]
+		res.Values[player] = r.Values[i]
+		res.Bounds[player] = r.Stderrs[i]
+	}
+
+	return res
+}
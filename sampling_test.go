@@ -0,0 +1,36 @@
+package shapley
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func Test_SampleShapley(t *testing.T) {
+	// v(S) = (Σ_{i∈S} w_i)² has φ_i = w_i·Σw
+	const n = 200
+	weights := make([]float64, n)
+	var total float64
+	for i := range weights {
+		weights[i] = float64(i%7 + 1)
+		total += weights[i]
+	}
+	value := func(ctx context.Context, S BigCoalition) (float64, error) {
+		var sum float64
+		for _, i := range S.Members() {
+			sum += weights[i]
+		}
+		return sum * sum, nil
+	}
+
+	res, err := SampleShapley(context.Background(), n, value, 200, rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("SampleShapley() error = %v", err)
+	}
+	for i, w := range weights {
+		if want := w * total; math.Abs(res.Values[i]-want) > 5*res.Stderrs[i]+1e-9 {
+			t.Errorf("SampleShapley()[%d] = %v ± %v, want %v", i, res.Values[i], res.Stderrs[i], want)
+		}
+	}
+}
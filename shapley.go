@@ -0,0 +1,550 @@
+// Package shapley computes the Shapley values of TU games. A game is read from rows of
+// "<space-separated players>,<Harsanyi dividend>" ordered by coalition size, the last row is the grand coalition.
+package shapley
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const epsilon = 1e-9
+
+// MaxPlayers is the largest number of players of an exact computation, which holds the worths of all 2^n coalitions.
+const MaxPlayers = 16
+
+// Result is the Shapley value of every player with an estimated bound of its rounding error.
+type Result struct {
+	Values map[string]float64
+	Bounds map[string]float64
+	Sum    float64 // Σ φ_i, v(N) up to rounding
+	// Coverage is the share of the coalitions whose marginal contributions are in the values,
+	// below one for the partial result of a canceled computation.
+	Coverage float64
+}
+
+// CheckNormalized fails unless the values sum to one, as they do for a game with v(N) = 1.
+func (r *Result) CheckNormalized() error {
+	if notEqualsOne(r.Sum) {
+		return fmt.Errorf("sum of Shapley values isn't equal to one, %v", r.Sum)
+	}
+
+	return nil
+}
+
+// CheckEfficient fails unless the values sum to v(N) of g.
+func (r *Result) CheckEfficient(g *Game) error {
+	return checkEfficient(r.Sum, g.Worths[g.Grand()])
+}
+
+// checkEfficient fails unless sum equals v(N) grand up to epsilon of max(1, |v(N)|).
+func checkEfficient(sum, grand float64) error {
+	if math.Abs(sum-grand) > epsilon*math.Max(1, math.Abs(grand)) {
+		return fmt.Errorf("sum of Shapley values %v isn't equal to v(N) %v", sum, grand)
+	}
+
+	return nil
+}
+
+// Solver computes Shapley values, the zero value is ready to use.
+type Solver struct {
+	// WeightPrec computes the Shapley weights with math/big at this precision instead of in log space.
+	WeightPrec uint
+	// Progress, when set, is called now and then with the number of coalitions processed of total.
+	Progress func(done, total int)
+	// Workers is the number of goroutines of a computation, GOMAXPROCS when it isn't positive.
+	Workers int
+	// Checkpoint, when set, is the file the state of the computation is saved to at least every CheckpointEvery
+	// and when it stops. A computation resumes from the state of an existing file and gives the same values
+	// to the last bit as an uninterrupted one.
+	Checkpoint      string
+	CheckpointEvery time.Duration
+}
+
+// Shapley returns the Shapley values of g.
+func (s Solver) Shapley(g *Game) (*Result, error) {
+	return s.ShapleyContext(context.Background(), g)
+}
+
+// ShapleyContext returns the Shapley values of g. When ctx is done first it stops the workers and returns
+// the partial result of the coalitions processed so far together with the error of ctx.
+func (s Solver) ShapleyContext(ctx context.Context, g *Game) (*Result, error) {
+	n := len(g.Players)
+	if n == 0 || n > MaxPlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+	cp, err := s.chunks(ctx, g.Players, denseWorths(n, g.Worths), handleRoundoff, 0, numChunks(n), false)
+	if cp == nil {
+		return nil, err
+	}
+	values, bounds, sum := cp.values()
+	res := &Result{Values: values, Bounds: bounds, Sum: sum, Coverage: 1}
+	if cp.Next < cp.To {
+		res.Coverage = float64(cp.done()) / float64(g.Grand())
+		return res, err
+	}
+
+	return res, nil
+}
+
+// ShapleyShard computes shard index of count shards of g, a range of about 1/count of the coalitions, and returns
+// its partial sums for MergeShards. When ctx is done first it returns the partial sums so far with the error of ctx.
+func (s Solver) ShapleyShard(ctx context.Context, g *Game, index, count int) (*Checkpoint, error) {
+	n := len(g.Players)
+	if n == 0 || n > MaxPlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+
+	return s.shard(ctx, g.Players, denseWorths(n, g.Worths), handleRoundoff, index, count)
+}
+
+// shard computes shard index of count shards of the worths of table as ShapleyShard does.
+func (s Solver) shard(ctx context.Context, players []string, table []float64, worthRel float64, index, count int) (*Checkpoint, error) {
+	n := len(players)
+	chunks := numChunks(n)
+	if count < 1 || count > chunks {
+		return nil, fmt.Errorf("number of shards of %d players must be between 1 and %d, %d", n, chunks, count)
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("shard must be between 0 and %d, %d", count-1, index)
+	}
+
+	return s.chunks(ctx, players, table, worthRel, index*chunks/count, (index+1)*chunks/count, true)
+}
+
+// chunks adds the chunks from up to to of the worths of table to a new checkpoint, or to the one of s.Checkpoint
+// when the file exists. worthRel is the relative rounding error of a worth. The checkpoint has the fingerprint
+// of the game when it is saved or marked. When ctx is done first it returns the checkpoint with the error of ctx,
+// on other errors no checkpoint.
+func (s Solver) chunks(ctx context.Context, players []string, table []float64, worthRel float64, from, to int,
+	mark bool) (*Checkpoint, error) {
+	n := len(players)
+	cp := newCheckpoint(players, s.WeightPrec, from, to)
+	cp.Grand = table[len(table)-1]
+	if mark || s.Checkpoint != "" {
+		cp.Fingerprint = fingerprint(players, table)
+	}
+	save := func() error { return nil }
+	if s.Checkpoint != "" {
+		prev, err := LoadCheckpoint(s.Checkpoint)
+		switch {
+		case err == nil:
+			if err := prev.resumes(cp); err != nil {
+				return nil, err
+			}
+			cp = prev
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+		saved := time.Now()
+		save = func() error {
+			if time.Since(saved) < s.CheckpointEvery && cp.Next < cp.To {
+				return nil
+			}
+			saved = time.Now()
+			return cp.Save(s.Checkpoint)
+		}
+	}
+
+	err := cp.advance(ctx, table, nil, worthRel, s.Workers, func() error {
+		if s.Progress != nil {
+			s.Progress(cp.done(), coalitionsOf(n, cp.From, cp.To))
+		}
+		return save()
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+	if err != nil && s.Checkpoint != "" {
+		if err := cp.Save(s.Checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	return cp, err
+}
+
+// Shapley returns the Shapley values of g with the zero Solver.
+func Shapley(g *Game) (*Result, error) {
+	return Solver{}.Shapley(g)
+}
+
+// LoadGame reads the game of a data file.
+func LoadGame(path string) (*Game, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open csv file, %w", err)
+	}
+	defer func() {
+		if err = f.Close(); err != nil {
+			log.Printf("[WARN] closing file: %v", err)
+		}
+	}()
+
+	return ReadGame(f)
+}
+
+// ReadGame reads the game of the rows of r.
+func ReadGame(r io.Reader) (*Game, error) {
+	records, err := ReadRecords(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseGame(records)
+}
+
+// ReadRecords splits the rows of r into their comma-separated columns, at least a coalition and a dividend each.
+func ReadRecords(r io.Reader) ([][]string, error) {
+	records, err := prepare(r, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare data, %w", err)
+	}
+
+	return records, nil
+}
+
+// ParseGame sums the dividends of records into the worths of a game.
+func ParseGame(records [][]string) (*Game, error) {
+	if err := checkRecords(records); err != nil {
+		return nil, err
+	}
+	players, _, worths, err := handle(records)
+	if err != nil {
+		return nil, fmt.Errorf("failed to handle data, %w", err)
+	}
+
+	return &Game{Players: players, Worths: worths}, nil
+}
+
+// checkRecords fails when there are no records or the grand coalition of the last one has too many players.
+func checkRecords(records [][]string) error {
+	if len(records) == 0 {
+		return errors.New("no records")
+	}
+	if n := len(strings.Fields(records[len(records)-1][0])); n == 0 || n > MaxPlayers {
+		return fmt.Errorf("number of players must be between 1 and %d, %d", MaxPlayers, n)
+	}
+
+	return nil
+}
+
+func prepare(r io.Reader, g int) ([][]string, error) {
+	records := make([][]string, 0, (1<<g)-1)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		record := strings.Split(sc.Text(), ",")
+		if l := len(record); l < 2 {
+			return nil, fmt.Errorf("length of row less 2, %d", l)
+		}
+		records = append(records, record)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens, %w", err)
+	}
+
+	return records, nil
+}
+
+// handle sums the dividends of records into the worths of all coalitions, v(S) = Σ_{T⊆S} d(T), by the zeta transform
+// of their compensated sums one player at a time, so a worth is accurate to handleRoundoff.
+func handle(records [][]string) (players []string, bitset []Coalition, worths map[Coalition]float64, err error) {
+	players, index, err := rowPlayers(records)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	bitset = make([]Coalition, len(players))
+	for i := range bitset {
+		bitset[i] = Singleton(i)
+	}
+
+	sums := make([]neumaier, 1<<len(players))
+	for _, rec := range records {
+		coalition, err := parseCoalition(rec[0], index)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		cValue, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to convert string to float, %w", err)
+		}
+		sums[coalition] = neumaier{sum: cValue}
+	}
+	for _, bs := range bitset {
+		for S := range sums {
+			if Coalition(S)&bs != 0 {
+				sums[S].merge(sums[Coalition(S)&^bs])
+			}
+		}
+	}
+
+	worths = make(map[Coalition]float64, len(sums)-1)
+	for S := 1; S < len(sums); S++ {
+		worths[Coalition(S)] = sums[S].result()
+	}
+
+	return players, bitset, worths, nil
+}
+
+func shapley(players []string, bitset []Coalition, worths map[Coalition]float64) (map[string]float64, float64) {
+	sValues, _, vSum := shapleyBounds(players, bitset, worths, 0)
+	return sValues, vSum
+}
+
+// shapleyBounds is shapley with the weights of prec that also estimates the rounding error of every value. The bound covers
+// the rounding of the worths (2u|v| each, as handle sums them with compensation), of the weights,
+// of every marginal contribution and product, and of the compensated sum itself (2u|φ|).
+func shapleyBounds(players []string, bitset []Coalition, worths map[Coalition]float64,
+	prec uint) (sValues, bounds map[string]float64, vSum float64) {
+	sValues, bounds, vSum, _ = shapleyContext(context.Background(), players, bitset, worths, prec, nil)
+	return sValues, bounds, vSum
+}
+
+// shapleyContext is shapleyBounds that stops early when ctx is done and reports progress, done is the number of
+// the coalitions 1, 2, ... in the values, all 2^n - 1 of them unless ctx was done first.
+func shapleyContext(ctx context.Context, players []string, bitset []Coalition, worths map[Coalition]float64, prec uint,
+	progress func(done, total int)) (sValues, bounds map[string]float64, vSum float64, done int) {
+	n := len(players)
+	cp := newCheckpoint(players, prec, 0, numChunks(n))
+	cp.advance(ctx, denseWorths(n, worths), bitset, handleRoundoff, 0, func() error {
+		if progress != nil {
+			progress(cp.done(), coalitionsOf(n, cp.From, cp.To))
+		}
+		return nil
+	})
+	sValues, bounds, vSum = cp.values()
+
+	return sValues, bounds, vSum, cp.done()
+}
+
+// handleRoundoff is the relative rounding error of a worth that handle sums with compensation.
+const handleRoundoff = 2 * unitRoundoff
+
+// chunkBits is log2 of the number of coalitions of a chunk. The terms of a chunk are summed per player and the sums
+// of the chunks are added in chunk order, so a computation resumed from a checkpoint adds the same numbers
+// in the same order as an uninterrupted one.
+const chunkBits = 12
+
+// leafBits is log2 of the number of coalitions whose terms chunkTerms sums in order. A larger range is summed
+// as its two halves, which are then merged, so the parts a chunk is split into don't change its sums.
+const leafBits = 6
+
+// cancelMask selects the coalitions at which chunkTerms checks for cancellation, one in 256.
+const cancelMask = 1<<8 - 1
+
+// numChunks returns the number of chunks of the coalitions of n players.
+func numChunks(n int) int {
+	if n <= chunkBits {
+		return 1
+	}
+
+	return 1 << (n - chunkBits)
+}
+
+// chunkRange returns the coalitions lo <= S < hi of chunk c of n players, empty past the last chunk.
+func chunkRange(n, c int) (lo, hi Coalition) {
+	lo, hi = Coalition(c)<<chunkBits, Coalition(c+1)<<chunkBits
+	if lo > Grand(n) {
+		lo = Grand(n) + 1
+	}
+	if hi > Grand(n) {
+		hi = Grand(n) + 1
+	}
+
+	return lo, hi
+}
+
+// coalitionsOf returns the number of non-empty coalitions of the chunks from up to to.
+func coalitionsOf(n, from, to int) int {
+	lo, _ := chunkRange(n, from)
+	hi, _ := chunkRange(n, to)
+	if from == 0 && to > 0 {
+		lo++
+	}
+
+	return int(hi - lo)
+}
+
+// denseWorths returns the worths of the coalitions of n players indexed by coalition.
+func denseWorths(n int, worths map[Coalition]float64) []float64 {
+	table := make([]float64, 1<<n)
+	for S, worth := range worths {
+		table[S] = worth
+	}
+
+	return table
+}
+
+// advance adds the chunks from cp.Next up to cp.To to the sums of cp and calls after once a chunk is added.
+// It returns the error of after, or the error of ctx when it is done before the last chunk. The workers stop
+// within a chunk when ctx is done, and the chunks they didn't finish aren't added.
+//
+// A pool of workers, GOMAXPROCS when workers isn't positive, sums the terms of a part of a chunk and a player
+// at a time, a batch of chunks with at least a task per worker at once. When the chunks are fewer than that,
+// as for the single chunk of up to chunkBits players, each is split into aligned halves until there are enough
+// tasks, and the parts are merged as chunkTerms merges the halves of a range. The sums of a batch are added
+// chunk by chunk in order, so the values are the same to the last bit whatever the number of workers.
+func (cp *Checkpoint) advance(ctx context.Context, table []float64, bitset []Coalition, worthRel float64, workers int,
+	after func() error) error {
+	n := len(cp.Sums)
+	if len(bitset) != n {
+		// bit i is players[i] as in handle
+		bitset = make([]Coalition, n)
+		for i := range bitset {
+			bitset[i] = Singleton(i)
+		}
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	weight := makeWeight(n, cp.WeightPrec)
+	relWeight := weightError(n, cp.WeightPrec) + 2*unitRoundoff // weight, subtraction and product
+
+	batch := (workers + n - 1) / n
+	if rest := cp.To - cp.Next; rest < batch {
+		batch = rest
+	}
+	chunkSize := Coalition(1) << chunkBits
+	if n < chunkBits {
+		chunkSize = Coalition(1) << n
+	}
+	partBits := 0
+	for batch<<partBits*n < workers && chunkSize>>partBits > 1<<leafBits {
+		partBits++
+	}
+	parts, partSize := 1<<partBits, chunkSize>>partBits
+	states := make([]termSum, batch*parts*n)
+	finished := make([]bool, batch*parts*n)
+	done := ctx.Done()
+	type task struct {
+		lo, hi Coalition
+		slot   int // (chunk of the batch·parts + part)·n + player
+	}
+	tasks := make(chan task)
+	defer close(tasks)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		go func() {
+			for t := range tasks {
+				states[t.slot], finished[t.slot] = chunkTerms(table, t.lo, t.hi, bitset[t.slot%n], weight, worthRel,
+					relWeight, done)
+				wg.Done()
+			}
+		}()
+	}
+
+	for cp.Next < cp.To {
+		size := batch
+		if rest := cp.To - cp.Next; rest < size {
+			size = rest
+		}
+		wg.Add(size * parts * n)
+		for k := 0; k < size; k++ {
+			lo, _ := chunkRange(n, cp.Next+k)
+			for p := 0; p < parts; p++ {
+				pLo := lo + Coalition(p)*partSize
+				for i := 0; i < n; i++ {
+					tasks <- task{lo: pLo, hi: pLo + partSize, slot: (k*parts+p)*n + i}
+				}
+			}
+		}
+		wg.Wait()
+
+		for k := 0; k < size; k++ {
+			chunk := states[k*parts*n : (k+1)*parts*n]
+			for _, ok := range finished[k*parts*n : (k+1)*parts*n] {
+				if !ok {
+					return ctx.Err()
+				}
+			}
+			for i := 0; i < n; i++ {
+				for width := 1; width < parts; width *= 2 {
+					for p := 0; p < parts; p += 2 * width {
+						chunk[p*n+i].merge(chunk[(p+width)*n+i])
+					}
+				}
+				sum := chunk[i].sum.result()
+				pSum := neumaier{sum: cp.Sums[i], c: cp.Comps[i]}
+				pSum.add(sum)
+				cp.Sums[i], cp.Comps[i] = pSum.sum, pSum.c
+				cp.Errs[i] += chunk[i].err + 2*unitRoundoff*math.Abs(sum)
+			}
+			cp.Next++
+			if err := after(); err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil && cp.Next < cp.To {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// termSum is the compensated sum of the terms of a range of coalitions and a bound of its rounding error.
+type termSum struct {
+	sum neumaier
+	err float64
+}
+
+// merge adds the terms of u to t.
+func (t *termSum) merge(u termSum) {
+	t.sum.merge(u.sum)
+	t.err += u.err
+}
+
+// chunkTerms returns the sum of the weighted marginal contributions of the player of bs to the coalitions lo <= S < hi
+// and a bound of its rounding error, for worths accurate to worthRel. It gives up when done is closed first.
+func chunkTerms(table []float64, lo, hi, bs Coalition, weight func(k int) float64, worthRel, relWeight float64,
+	done <-chan struct{}) (termSum, bool) {
+	if hi-lo > 1<<leafBits {
+		mid := lo + (hi-lo)/2
+		left, ok := chunkTerms(table, lo, mid, bs, weight, worthRel, relWeight, done)
+		if !ok {
+			return left, false
+		}
+		right, ok := chunkTerms(table, mid, hi, bs, weight, worthRel, relWeight, done)
+		left.merge(right)
+		return left, ok
+	}
+
+	var ts termSum
+	for S := lo; S < hi; S++ {
+		if S&cancelMask == 0 {
+			select {
+			case <-done:
+				return ts, false
+			default:
+			}
+		}
+		if S&bs != 0 {
+			continue
+		}
+
+		// Weight = |S|!(n-|S|-1)!/n!
+		w := weight(S.Size())
+		// Marginal contribution = v(S U {i})-v(S)
+		vS, vSi := table[S], table[S|bs]
+		term := w * (vSi - vS)
+		ts.sum.add(term)
+		ts.err += w*worthRel*(math.Abs(vSi)+math.Abs(vS)) + relWeight*math.Abs(term)
+	}
+
+	return ts, true
+}
+
+func notEqualsOne(f float64) bool {
+	return math.Abs(f-1) > epsilon
+}
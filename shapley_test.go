@@ -0,0 +1,269 @@
+package shapley
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const data = `
+Google,0.18
+Meta,0.04
+Microsoft,0.08
+Meta Google,0.1
+Microsoft Google,0.26
+Meta Microsoft,0.07
+Meta Microsoft Google,0.27
+`
+
+func mockData() io.Reader {
+	return strings.NewReader(strings.TrimSpace(data))
+}
+
+func mockRecords() [][]string {
+	return [][]string{{"Google", "0.18"}, {"Meta", "0.04"}, {"Microsoft", "0.08"}, {"Meta Google", "0.1"}, {"Microsoft Google", "0.26"}, {"Meta Microsoft", "0.07"}, {"Meta Microsoft Google", "0.27"}}
+}
+
+func mockPlayers() []string {
+	return []string{"Google", "Meta", "Microsoft"}
+}
+
+func mockBitset() []Coalition {
+	return []Coalition{0b1, 0b10, 0b100}
+}
+
+func mockWorths() map[Coalition]float64 {
+	// "Google": 0.18, "Google Meta": 0.32, "Google Meta Microsoft": 1, "Google Microsoft": 0.52, "Meta": 0.04, "Meta Microsoft": 0.19, "Microsoft": 0.08
+	return map[Coalition]float64{0b1: 0.18, 0b11: 0.32, 0b111: 1, 0b101: 0.52, 0b10: 0.04, 0b110: 0.19, 0b100: 0.08}
+}
+
+func mockReader() (io.Reader, int) {
+	f, _ := os.ReadFile("data/N11")
+	return bytes.NewReader(f), 11
+}
+
+func Test_prepare(t *testing.T) {
+	type args struct {
+		r io.Reader
+		g int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    [][]string
+		wantErr bool
+	}{
+		{
+			name: "simple",
+			args: args{r: mockData(), g: 3},
+			want: mockRecords(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := prepare(tt.args.r, tt.args.g)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("prepare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("prepare() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_handle(t *testing.T) {
+	type args struct {
+		records [][]string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantPlayers []string
+		wantBitset  []Coalition
+		wantWorths  map[Coalition]float64
+		wantErr     bool
+	}{
+		{
+			name:        "simple",
+			args:        args{mockRecords()},
+			wantPlayers: mockPlayers(),
+			wantBitset:  mockBitset(),
+			wantWorths:  mockWorths(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPlayers, gotBitset, gotWorths, err := handle(tt.args.records)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotPlayers, tt.wantPlayers) {
+				t.Errorf("handle() gotPlayers = %v, want %v", gotPlayers, tt.wantPlayers)
+			}
+			if !reflect.DeepEqual(gotBitset, tt.wantBitset) {
+				t.Errorf("handle() gotBitset = %v, want %v", gotBitset, tt.wantBitset)
+			}
+			for key, value := range gotWorths {
+				if wantValue := tt.wantWorths[key]; math.Abs(wantValue-value) > 1e-9 {
+					t.Errorf("wantValue = %v, gotValue = %v", wantValue, value)
+				}
+			}
+		})
+	}
+}
+
+func Test_shapley(t *testing.T) {
+	type args struct {
+		players []string
+		bitset  []Coalition
+		worths  map[Coalition]float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want map[string]float64
+	}{
+		{
+			name: "simple",
+			args: args{players: mockPlayers(), bitset: mockBitset(), worths: mockWorths()},
+			want: map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335},
+		},
+		{
+			name: "nil bitset",
+			args: args{players: mockPlayers(), worths: mockWorths()},
+			want: map[string]float64{"Google": 0.45, "Meta": 0.215, "Microsoft": 0.335},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := shapley(tt.args.players, tt.args.bitset, tt.args.worths)
+			for key, value := range got {
+				if wantValue := tt.want[key]; math.Abs(wantValue-value) > 1e-9 {
+					t.Errorf("wantValue = %v, gotValue = %v", wantValue, value)
+				}
+			}
+			if notEqualsOne(got1) {
+				t.Errorf("shapley() got1 = %v, want 1", got1)
+			}
+		})
+	}
+}
+
+func Test_shapleyClassic(t *testing.T) {
+	tests := []struct {
+		game func() (*Game, map[string]float64)
+		name string
+	}{
+		{name: "glove 1-2", game: func() (*Game, map[string]float64) { return GloveGame(1, 2) }},
+		{name: "glove 3-5", game: func() (*Game, map[string]float64) { return GloveGame(3, 5) }},
+		{name: "glove 4-4", game: func() (*Game, map[string]float64) { return GloveGame(4, 4) }},
+		{name: "airport", game: func() (*Game, map[string]float64) { return AirportGame([]float64{3, 1, 4, 1, 5, 9}) }},
+		{name: "bankruptcy", game: func() (*Game, map[string]float64) { return BankruptcyGame(200, []float64{100, 200, 300}) }},
+		{name: "bankruptcy large", game: func() (*Game, map[string]float64) { return BankruptcyGame(7, []float64{1, 2, 3, 4, 5}) }},
+		{name: "unanimity", game: func() (*Game, map[string]float64) { return UnanimityGame(7, 3) }},
+		{name: "majority", game: func() (*Game, map[string]float64) { return MajorityGame(8, 5) }},
+		{name: "apex", game: func() (*Game, map[string]float64) { return ApexGame(6) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, want := tt.game()
+			got, _ := shapley(g.Players, g.bits(), g.Worths)
+			for key, wantValue := range want {
+				if value := got[key]; math.Abs(wantValue-value) > 1e-9 {
+					t.Errorf("%s: wantValue = %v, gotValue = %v", key, wantValue, value)
+				}
+			}
+		})
+	}
+}
+
+func Test_ShapleyContext(t *testing.T) {
+	g, want := UnanimityGame(13, 3)
+	var calls, last int
+	s := Solver{Progress: func(done, total int) {
+		if done < last || total != 8191 {
+			t.Errorf("Progress(%d, %d) after %d", done, total, last)
+		}
+		calls++
+		last = done
+	}}
+	res, err := s.ShapleyContext(context.Background(), g)
+	if err != nil || res.Coverage != 1 {
+		t.Fatalf("ShapleyContext() coverage = %v, error = %v", res.Coverage, err)
+	}
+	if calls < 2 || last != 8191 {
+		t.Errorf("Progress() called %d times, last with %d", calls, last)
+	}
+	for key, wantValue := range want {
+		if value := res.Values[key]; math.Abs(wantValue-value) > 1e-9 {
+			t.Errorf("%s: wantValue = %v, gotValue = %v", key, wantValue, value)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, g := range []*Game{g, NewGame(NumberedPlayers("P", 9))} {
+		// 9 players are a single chunk, the workers stop within it
+		res, err = Solver{}.ShapleyContext(ctx, g)
+		if !errors.Is(err, context.Canceled) || res == nil || res.Coverage >= 1 {
+			t.Errorf("ShapleyContext() of %d players of a canceled context = %+v, error = %v", len(g.Players), res, err)
+		}
+	}
+}
+
+func Test_SolverWorkers(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	// 9 players are a single chunk, split into parts for the workers
+	for _, n := range []int{15, 9} {
+		g := NewGame(NumberedPlayers("P", n))
+		for S := Coalition(1); S <= g.Grand(); S++ {
+			g.Worths[S] = rng.NormFloat64()
+		}
+		want, _ := Solver{Workers: 1}.Shapley(g)
+		for _, workers := range []int{2, 7, 64, 0} {
+			got, err := Solver{Workers: workers}.Shapley(g)
+			if err != nil {
+				t.Fatalf("Shapley() with %d workers error = %v", workers, err)
+			}
+			for _, player := range g.Players {
+				if got.Values[player] != want.Values[player] || got.Bounds[player] != want.Bounds[player] {
+					t.Errorf("%d players, %d workers: %s = %v, want %v", n, workers, player, got.Values[player],
+						want.Values[player])
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkPrepare(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		prepare(mockReader())
+	}
+}
+
+func BenchmarkHandle(b *testing.B) {
+	records, _ := prepare(mockReader())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handle(records)
+	}
+}
+
+func BenchmarkShapley(b *testing.B) {
+	records, _ := prepare(mockReader())
+	players, bitset, worths, _ := handle(records)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		shapley(players, bitset, worths)
+	}
+}
@@ -0,0 +1,32 @@
+package shapley
+
+import "math"
+
+// unitRoundoff is the relative rounding error of a float64 operation, 2^-53.
+const unitRoundoff = 0x1p-53
+
+// neumaier is a compensated sum: c collects the low-order bits that rounding drops from sum,
+// so the result stays accurate to about one rounding of the total however many terms are added.
+type neumaier struct {
+	sum, c float64
+}
+
+func (s *neumaier) add(x float64) {
+	t := s.sum + x
+	if math.Abs(s.sum) >= math.Abs(x) {
+		s.c += (s.sum - t) + x
+	} else {
+		s.c += (x - t) + s.sum
+	}
+	s.sum = t
+}
+
+// merge adds the sum t to s.
+func (s *neumaier) merge(t neumaier) {
+	s.add(t.sum)
+	s.c += t.c
+}
+
+func (s *neumaier) result() float64 {
+	return s.sum + s.c
+}
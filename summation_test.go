@@ -0,0 +1,43 @@
+package shapley
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_neumaier(t *testing.T) {
+	tests := []struct {
+		name  string
+		terms []float64
+		want  float64
+	}{
+		{name: "cancellation", terms: []float64{1, 1e100, 1, -1e100}, want: 2},
+		{name: "small terms", terms: []float64{1, 1e-16, 1e-16, 1e-16, 1e-16}, want: 1 + 4e-16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s neumaier
+			for _, x := range tt.terms {
+				s.add(x)
+			}
+			if got := s.result(); got != tt.want {
+				t.Errorf("result() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shapleyBounds(t *testing.T) {
+	records, _ := prepare(mockReader())
+	players, bitset, worths, _ := handle(records)
+	got, bounds, _ := shapleyBounds(players, bitset, worths, 0)
+
+	_, _, ratWorths, _ := handleExact(records)
+	want, _ := shapleyExact(players, bitset, ratWorths)
+	for _, player := range players {
+		exactValue, _ := want[player].Float64()
+		if diff := math.Abs(got[player] - exactValue); diff > bounds[player] || bounds[player] > 1e-12 {
+			t.Errorf("%s: error %g, bound %g", player, diff, bounds[player])
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package shapley
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// MaxTablePlayers is the largest number of players of a Table, whose 2^n worths take 8·2^n bytes of disk.
+const MaxTablePlayers = 36
+
+// tableMagic starts a table file. The magic, the sizes of the players and the source hash that follow it are
+// little-endian, the worths are native float64s from tableOffset on.
+const tableMagic = "SHAPTBL2"
+
+// tableAlign is the alignment of the worths in a table file, a multiple of the page sizes.
+const tableAlign = 1 << 16
+
+// Table is the worths of all coalitions of players indexed by coalition in a memory-mapped file, so a game
+// can be larger than the memory. Where the file can't be mapped it is read into memory instead.
+type Table struct {
+	Players  []string
+	Worths   []float64
+	Source   uint64 // FNV-1a hash of the data file the table was built from
+	data     []byte
+	file     *os.File
+	writable bool // built, written back on Close
+}
+
+// BuildTable reads the rows of the data file into a new table file and returns it open. The worths are summed
+// from the dividends in place one player at a time, so they are accurate to a rounding per player. The table is
+// built in a temporary file next to tablePath and renamed to it when complete, so a failed build leaves nothing
+// behind and a concurrent OpenTable never sees a partial table.
+func BuildTable(dataPath, tablePath string) (*Table, error) {
+	in, err := os.Open(dataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open csv file, %w", err)
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.Printf("[WARN] closing file: %v", err)
+		}
+	}()
+
+	h := fnv.New64a()
+	players, err := lastPlayers(io.TeeReader(in, h))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind csv file, %w", err)
+	}
+
+	header := tableHeader(players, h.Sum64())
+	size := tableOffset(len(header)) + 8<<len(players)
+	f, err := os.CreateTemp(filepath.Dir(tablePath), filepath.Base(tablePath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table file, %w", err)
+	}
+	tmpPath := f.Name()
+	if err := f.Truncate(int64(size)); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to size table file, %w", err)
+	}
+	t, err := mapTable(f, size, true)
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	copy(t.data, header)
+
+	err = handleTable(in, players, worthsOf(t.data[tableOffset(len(header)):]))
+	if cerr := t.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, tablePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to build table, %w", err)
+	}
+
+	return OpenTable(tablePath)
+}
+
+// OpenTable maps an existing table file for reading.
+func OpenTable(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open table file, %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat table file, %w", err)
+	}
+	t, err := mapTable(f, int(info.Size()), false)
+	if err != nil {
+		return nil, err
+	}
+
+	players, source, headerLen, err := parseTableHeader(t.data)
+	if err != nil {
+		t.Close()
+		return nil, fmt.Errorf("table file %s: %w", path, err)
+	}
+	if want := tableOffset(headerLen) + 8<<len(players); len(t.data) != want {
+		t.Close()
+		return nil, fmt.Errorf("table file %s has %d bytes instead of %d", path, len(t.data), want)
+	}
+	t.Players, t.Source = players, source
+	t.Worths = worthsOf(t.data[tableOffset(headerLen):])
+
+	return t, nil
+}
+
+// BuiltFrom reports whether t was built from the current contents of the data file, by their hash.
+func (t *Table) BuiltFrom(dataPath string) (bool, error) {
+	in, err := os.Open(dataPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open csv file, %w", err)
+	}
+	defer in.Close()
+	h := fnv.New64a()
+	if _, err := io.Copy(h, in); err != nil {
+		return false, fmt.Errorf("failed to read csv file, %w", err)
+	}
+
+	return h.Sum64() == t.Source, nil
+}
+
+// Close unmaps the table, writing back the worths of a built table, and closes its file.
+func (t *Table) Close() error {
+	err := unmapTable(t)
+	if cerr := t.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close table file, %w", cerr)
+	}
+	t.data, t.Worths = nil, nil
+
+	return err
+}
+
+// ShapleyTable returns the Shapley values of the game of t as ShapleyContext does. The chunks read the worths
+// in ascending order of coalition, a sequential pass over the file per chunk and player.
+func (s Solver) ShapleyTable(ctx context.Context, t *Table) (*Result, error) {
+	n := len(t.Players)
+	if n == 0 || n > MaxTablePlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxTablePlayers, n)
+	}
+	cp, err := s.chunks(ctx, t.Players, t.Worths, tableRoundoff(n), 0, numChunks(n), false)
+	if cp == nil {
+		return nil, err
+	}
+	values, bounds, sum := cp.values()
+	res := &Result{Values: values, Bounds: bounds, Sum: sum, Coverage: 1}
+	if cp.Next < cp.To {
+		res.Coverage = float64(cp.done()) / float64(Grand(n))
+		return res, err
+	}
+
+	return res, nil
+}
+
+// ShapleyTableShard computes shard index of count shards of the game of t as ShapleyShard does, for games
+// of more than MaxPlayers players.
+func (s Solver) ShapleyTableShard(ctx context.Context, t *Table, index, count int) (*Checkpoint, error) {
+	n := len(t.Players)
+	if n == 0 || n > MaxTablePlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxTablePlayers, n)
+	}
+
+	return s.shard(ctx, t.Players, t.Worths, tableRoundoff(n), index, count)
+}
+
+// tableRoundoff is the relative rounding error of a worth of a table of n players, which BuildTable sums
+// from the dividends with a rounding per player.
+func tableRoundoff(n int) float64 {
+	return float64(n+1) * unitRoundoff
+}
+
+// lastPlayers returns the sorted players of the grand coalition of the last row of r.
+func lastPlayers(r io.Reader) ([]string, error) {
+	var last string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if line := sc.Text(); strings.TrimSpace(line) != "" {
+			last = line
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens, %w", err)
+	}
+
+	players := strings.Fields(strings.Split(last, ",")[0])
+	if n := len(players); n == 0 || n > MaxTablePlayers {
+		return nil, fmt.Errorf("number of players must be between 1 and %d, %d", MaxTablePlayers, n)
+	}
+	sort.Strings(players)
+
+	return players, nil
+}
+
+// handleTable is handle into the worths of a table: it puts the dividend of every row of r at its coalition
+// and then adds the dividends of the subsets of every coalition, v(S) = Σ_{T⊆S} d(T), one player at a time.
+func handleTable(r io.Reader, players []string, worths []float64) error {
+	index := playerIndex(players)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		record := strings.Split(sc.Text(), ",")
+		if l := len(record); l < 2 {
+			return fmt.Errorf("length of row less 2, %d", l)
+		}
+		coalition, err := parseCoalition(record[0], index)
+		if err != nil {
+			return err
+		}
+		dividend, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			return fmt.Errorf("failed to convert string to float, %w", err)
+		}
+		worths[coalition] = dividend
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to scan tokens, %w", err)
+	}
+
+	for i := range players {
+		bit := Singleton(i)
+		for S := range worths {
+			if Coalition(S)&bit != 0 {
+				worths[S] += worths[Coalition(S)^bit]
+			}
+		}
+	}
+
+	return nil
+}
+
+// tableHeader is the magic, the number of players, the length of their names and the hash of the source data
+// file, and the names one per line.
+func tableHeader(players []string, source uint64) []byte {
+	names := strings.Join(players, "\n")
+	header := make([]byte, 0, len(tableMagic)+24+len(names))
+	header = append(header, tableMagic...)
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(players)))
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(names)))
+	header = binary.LittleEndian.AppendUint64(header, source)
+
+	return append(header, names...)
+}
+
+// parseTableHeader returns the players and the source hash of the header at the start of data and the length
+// of the header.
+func parseTableHeader(data []byte) ([]string, uint64, int, error) {
+	const fixed = len(tableMagic) + 24
+	if len(data) < fixed || !bytes.Equal(data[:len(tableMagic)], []byte(tableMagic)) {
+		return nil, 0, 0, errors.New("not a table file")
+	}
+	n := binary.LittleEndian.Uint64(data[len(tableMagic):])
+	namesLen := binary.LittleEndian.Uint64(data[len(tableMagic)+8:])
+	source := binary.LittleEndian.Uint64(data[len(tableMagic)+16:])
+	if n == 0 || n > MaxTablePlayers || namesLen > uint64(len(data)-fixed) {
+		return nil, 0, 0, fmt.Errorf("header of %d players with %d bytes of names", n, namesLen)
+	}
+	players := strings.Split(string(data[fixed:fixed+int(namesLen)]), "\n")
+	if uint64(len(players)) != n {
+		return nil, 0, 0, fmt.Errorf("header of %d players with %d names", n, len(players))
+	}
+
+	return players, source, fixed + int(namesLen), nil
+}
+
+// tableOffset returns the offset of the worths after a header of headerLen bytes.
+func tableOffset(headerLen int) int {
+	return (headerLen + tableAlign - 1) / tableAlign * tableAlign
+}
+
+// worthsOf returns the float64s of data, which starts at a multiple of tableAlign of a mapping.
+func worthsOf(data []byte) []float64 {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return unsafe.Slice((*float64)(unsafe.Pointer(&data[0])), len(data)/8)
+}
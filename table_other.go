@@ -0,0 +1,38 @@
+//go:build !unix
+
+package shapley
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"unsafe"
+)
+
+// mapTable reads size bytes of f into memory where files can't be mapped.
+func mapTable(f *os.File, size int, writable bool) (*Table, error) {
+	// float64s of the worths need an aligned buffer
+	buf := make([]float64, (size+7)/8)
+	data := unsafe.Slice((*byte)(unsafe.Pointer(&buf[0])), size)
+	if _, err := io.ReadFull(f, data); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read table file, %w", err)
+	}
+
+	return &Table{data: data, file: f, writable: writable}, nil
+}
+
+// unmapTable writes the worths of a built table back to its file.
+func unmapTable(t *Table) error {
+	if !t.writable {
+		return nil
+	}
+	if _, err := t.file.WriteAt(t.data, 0); err != nil {
+		return fmt.Errorf("failed to write table file, %w", err)
+	}
+	if err := t.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync table file, %w", err)
+	}
+
+	return nil
+}
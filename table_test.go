@@ -0,0 +1,110 @@
+package shapley
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func Test_Table(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "N13")
+	players := GenePlayers(13)
+	d, _ := GenerateFamily("noisy", len(players), FamilyParams{Alpha: 1, Noise: 0.1}, rand.New(rand.NewSource(1)))
+	f, _ := os.Create(data)
+	if err := WriteDividends(f, players, d); err != nil {
+		t.Fatalf("WriteDividends() error = %v", err)
+	}
+	f.Close()
+
+	g, err := LoadGame(data)
+	if err != nil {
+		t.Fatalf("LoadGame() error = %v", err)
+	}
+	want, _ := Shapley(g)
+
+	path := filepath.Join(dir, "N13.table")
+	table, err := BuildTable(data, path)
+	if err != nil {
+		t.Fatalf("BuildTable() error = %v", err)
+	}
+	got, err := Solver{}.ShapleyTable(context.Background(), table)
+	if err != nil {
+		t.Fatalf("ShapleyTable() error = %v", err)
+	}
+	for _, player := range g.Players {
+		if diff := math.Abs(got.Values[player] - want.Values[player]); diff > got.Bounds[player]+want.Bounds[player] {
+			t.Errorf("%s: table %v ± %v, want %v ± %v", player, got.Values[player], got.Bounds[player],
+				want.Values[player], want.Bounds[player])
+		}
+	}
+	if err := table.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	table, err = OpenTable(path)
+	if err != nil {
+		t.Fatalf("OpenTable() error = %v", err)
+	}
+	defer table.Close()
+	reopened, _ := Solver{}.ShapleyTable(context.Background(), table)
+	for _, player := range g.Players {
+		if reopened.Values[player] != got.Values[player] {
+			t.Errorf("%s: reopened table %v, want %v", player, reopened.Values[player], got.Values[player])
+		}
+	}
+
+	shards := make([]*Checkpoint, 2)
+	for i := range shards {
+		if shards[i], err = (Solver{}).ShapleyTableShard(context.Background(), table, i, len(shards)); err != nil {
+			t.Fatalf("ShapleyTableShard(%d) error = %v", i, err)
+		}
+	}
+	merged, err := MergeShards(shards)
+	if err != nil {
+		t.Fatalf("MergeShards() of the table shards error = %v", err)
+	}
+	for _, player := range g.Players {
+		if diff := math.Abs(merged.Values[player] - got.Values[player]); diff > merged.Bounds[player]+got.Bounds[player] {
+			t.Errorf("%s: merged table shards %v, want %v", player, merged.Values[player], got.Values[player])
+		}
+	}
+
+	if same, err := table.BuiltFrom(data); err != nil || !same {
+		t.Errorf("BuiltFrom() of its data file = %v, error = %v", same, err)
+	}
+	if same, _ := table.BuiltFrom(filepath.Join("data", "N11")); same {
+		t.Errorf("BuiltFrom() of another data file = true")
+	}
+
+	// a missing coalition is a zero dividend, φ_i = Σ_{T∋i} d(T)/|T|
+	sparse := filepath.Join(dir, "sparse")
+	os.WriteFile(sparse, []byte("A,.2\nB,.3\nC,.1\nA C,.1\nB C,.1\nA B C,.2\n"), 0o644)
+	st, err := BuildTable(sparse, filepath.Join(dir, "sparse.table"))
+	if err != nil {
+		t.Fatalf("BuildTable() of a sparse file error = %v", err)
+	}
+	defer st.Close()
+	res, _ := Solver{}.ShapleyTable(context.Background(), st)
+	for player, want := range map[string]float64{"A": .2 + .1/2 + .2/3, "B": .3 + .1/2 + .2/3, "C": .1 + .1 + .2/3} {
+		if math.Abs(res.Values[player]-want) > 1e-12 {
+			t.Errorf("%s: sparse table %v, want %v", player, res.Values[player], want)
+		}
+	}
+
+	if _, err := OpenTable(data); err == nil {
+		t.Errorf("OpenTable() of a data file error = nil")
+	}
+	bad := filepath.Join(dir, "bad")
+	os.WriteFile(bad, []byte("A,0.5\nC,0.25\nA B,0.25\n"), 0o644)
+	if _, err := BuildTable(bad, filepath.Join(dir, "bad.table")); err == nil || !strings.Contains(err.Error(), "player C") {
+		t.Errorf("BuildTable() of an unknown player error = %v", err)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "bad.table*")); len(left) > 0 {
+		t.Errorf("BuildTable() left %v of a failed build", left)
+	}
+}
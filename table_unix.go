@@ -0,0 +1,38 @@
+//go:build unix
+
+package shapley
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapTable maps size bytes of f, shared and writable when the table is built.
+func mapTable(f *os.File, size int, writable bool) (*Table, error) {
+	prot := syscall.PROT_READ
+	if writable {
+		prot |= syscall.PROT_WRITE
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, size, prot, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to map table file, %w", err)
+	}
+
+	return &Table{data: data, file: f, writable: writable}, nil
+}
+
+// unmapTable unmaps the table and flushes the worths of a built table to its file.
+func unmapTable(t *Table) error {
+	if err := syscall.Munmap(t.data); err != nil {
+		return fmt.Errorf("failed to unmap table file, %w", err)
+	}
+	if t.writable {
+		if err := t.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync table file, %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package shapley
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RowCov is the covariance of the values of the rows of coalitions S and T.
+type RowCov struct {
+	S, T Coalition
+	Cov  float64
+}
+
+// RowStderrs reads the third column of every record of g as the standard error of its value, rows are independent.
+func RowStderrs(g *Game, records [][]string) ([]RowCov, error) {
+	index := playerIndex(g.Players)
+	covs := make([]RowCov, 0, len(records))
+	for line, rec := range records {
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("line %d: no standard error", line+1)
+		}
+		S, err := parseCoalition(rec[0], index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line+1, err)
+		}
+		se, err := strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to convert string to float, %w", line+1, err)
+		}
+		covs = append(covs, RowCov{S: S, T: S, Cov: se * se})
+	}
+
+	return covs, nil
+}
+
+// ReadCovariances reads "<players>,<players>,<covariance>" rows of the players of g. A covariance between two different rows
+// is given once and counts for both orders.
+func ReadCovariances(r io.Reader, g *Game) ([]RowCov, error) {
+	index := playerIndex(g.Players)
+	var covs []RowCov
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		rec := strings.Split(sc.Text(), ",")
+		if len(rec) != 3 {
+			return nil, fmt.Errorf("line %d: want 3 columns, %d", line, len(rec))
+		}
+		S, err := parseCoalition(rec[0], index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		T, err := parseCoalition(rec[1], index)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		cov, err := strconv.ParseFloat(strings.TrimSpace(rec[2]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: failed to convert string to float, %w", line, err)
+		}
+		covs = append(covs, RowCov{S: S, T: T, Cov: cov})
+		if S != T {
+			covs = append(covs, RowCov{S: T, T: S, Cov: cov})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens, %w", err)
+	}
+
+	return covs, nil
+}
+
+// Covariance returns the covariance matrix of the Shapley values of the players of g given the covariances of its rows.
+// The values are linear in the rows, φ_i = Σ_{S∋i} d_S/|S|, so Cov(φ_i, φ_j) = Σ_{S∋i} Σ_{T∋j} c_ST/(|S||T|) exactly.
+func Covariance(g *Game, covs []RowCov) [][]float64 {
+	bitset := g.bits()
+	n := len(bitset)
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	for _, c := range covs {
+		share := c.Cov / float64(c.S.Size()*c.T.Size())
+		for i, bi := range bitset {
+			if c.S&bi == 0 {
+				continue
+			}
+			for j, bj := range bitset {
+				if c.T&bj != 0 {
+					cov[i][j] += share
+				}
+			}
+		}
+	}
+
+	return cov
+}
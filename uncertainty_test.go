@@ -0,0 +1,73 @@
+package shapley
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_Covariance(t *testing.T) {
+	players, bitset, worths, _ := handle(mockRecords())
+	g := &Game{Players: players, Worths: worths}
+	data := "Google,Google,0.04\nMeta Google,Meta Google,0.01\nMeta Google,Meta Microsoft Google,-0.002"
+	covs, err := ReadCovariances(strings.NewReader(data), g)
+	if err != nil {
+		t.Fatalf("ReadCovariances() error = %v", err)
+	}
+	got := Covariance(g, covs)
+
+	// The Jacobian of the values by the rows, from shapley itself: J[i][S] = φ_i(v + u_S) - φ_i(v)
+	// where u_S is the unanimity game of S.
+	base, _ := shapley(players, bitset, worths)
+	jacobian := make(map[Coalition][]float64)
+	for s := 1; s < 1<<len(players); s++ {
+		S := Coalition(s)
+		shifted := make(map[Coalition]float64, len(worths))
+		for T, worth := range worths {
+			if T&S == S {
+				worth++
+			}
+			shifted[T] = worth
+		}
+		sValues, _ := shapley(players, bitset, shifted)
+		jacobian[S] = make([]float64, len(players))
+		for i, player := range players {
+			jacobian[S][i] = sValues[player] - base[player]
+		}
+	}
+
+	for i := range players {
+		for j := range players {
+			var want float64
+			for _, c := range covs {
+				want += jacobian[c.S][i] * c.Cov * jacobian[c.T][j]
+			}
+			if math.Abs(got[i][j]-want) > 1e-9 {
+				t.Errorf("cov[%d][%d] = %v, want %v", i, j, got[i][j], want)
+			}
+		}
+	}
+}
+
+func Test_RowStderrs(t *testing.T) {
+	records := mockRecords()
+	g := mockGame()
+	if _, err := RowStderrs(g, records); err == nil {
+		t.Errorf("RowStderrs() error = nil, want error")
+	}
+	for i := range records {
+		records[i] = append(records[i], strconv.Itoa(i))
+	}
+	covs, err := RowStderrs(g, records)
+	if err != nil {
+		t.Fatalf("RowStderrs() error = %v", err)
+	}
+	if c := covs[2]; c.S != 0b100 || c.T != 0b100 || c.Cov != 4 {
+		t.Errorf("RowStderrs()[2] = %v, want {4 4 4}", c)
+	}
+	records[0][0] = ""
+	if _, err := RowStderrs(g, records); err == nil {
+		t.Errorf("RowStderrs() of an empty coalition error = nil, want error")
+	}
+}
@@ -0,0 +1,377 @@
+package shapley
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// WeightedGame is a weighted voting game [quota; w1, ..., wn]: a coalition wins when its weight reaches the quota.
+type WeightedGame struct {
+	Name    string
+	Weights []int64
+	Quota   int64
+}
+
+// VotingGame is a boolean composition of weighted games over the same players,
+// e.g. a double-majority rule is the intersection of a "states" and a "population" game.
+type VotingGame struct {
+	rule    *ruleNode
+	Players []string
+	Games   []WeightedGame
+}
+
+// ruleNode is a node of a composition: a leaf refers to games[game],
+// an inner node combines its children with '&' (intersection) or '|' (union).
+type ruleNode struct {
+	left, right *ruleNode
+	game        int
+	op          byte
+}
+
+func (r *ruleNode) wins(sums []int64, games []WeightedGame) bool {
+	switch r.op {
+	case '&':
+		return r.left.wins(sums, games) && r.right.wins(sums, games)
+	case '|':
+		return r.left.wins(sums, games) || r.right.wins(sums, games)
+	default:
+		return sums[r.game] >= games[r.game].Quota
+	}
+}
+
+// ParseVoting reads a voting game specification:
+//
+//	# comment
+//	players A B C D
+//	game NAME QUOTA W_A W_B W_C W_D
+//	rule (NAME & NAME) | NAME
+//
+// Quota and weights of a game may be decimals; they are scaled to integers together.
+// Without a rule line the game is the intersection of all listed games.
+func ParseVoting(r io.Reader) (*VotingGame, error) {
+	vg := &VotingGame{}
+	var expr string
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "players":
+			if vg.Players != nil {
+				return nil, fmt.Errorf("line %d: players are already defined", line)
+			}
+			vg.Players = fields[1:]
+		case "game":
+			if l, want := len(fields), len(vg.Players)+3; len(vg.Players) == 0 || l != want {
+				return nil, fmt.Errorf("line %d: game needs a name, a quota and %d weights", line, len(vg.Players))
+			}
+			for _, g := range vg.Games {
+				if g.Name == fields[1] {
+					return nil, fmt.Errorf("line %d: game %q is already defined", line, g.Name)
+				}
+			}
+			nums, err := parseScaled(fields[2:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			vg.Games = append(vg.Games, WeightedGame{Name: fields[1], Quota: nums[0], Weights: nums[1:]})
+		case "rule":
+			expr = strings.Join(fields[1:], " ")
+		default:
+			return nil, fmt.Errorf("line %d: unknown directive %q", line, fields[0])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan tokens, %w", err)
+	}
+	if len(vg.Games) == 0 {
+		return nil, errors.New("no games defined")
+	}
+
+	if expr == "" {
+		names := make([]string, len(vg.Games))
+		for i, g := range vg.Games {
+			names[i] = g.Name
+		}
+		expr = strings.Join(names, " & ")
+	}
+	rule, err := parseRule(expr, vg.Games)
+	if err != nil {
+		return nil, err
+	}
+	vg.rule = rule
+
+	return vg, nil
+}
+
+// parseScaled parses non-negative decimals and multiplies them by a common power of ten so that all become integers.
+func parseScaled(fields []string) ([]int64, error) {
+	var scale int
+	for _, f := range fields {
+		if i := strings.IndexByte(f, '.'); i >= 0 && len(f)-i-1 > scale {
+			scale = len(f) - i - 1
+		}
+	}
+
+	nums := make([]int64, len(fields))
+	for i, f := range fields {
+		digits, frac := f, 0
+		if j := strings.IndexByte(f, '.'); j >= 0 {
+			digits, frac = f[:j]+f[j+1:], len(f)-j-1
+		}
+		num, err := strconv.ParseInt(digits+strings.Repeat("0", scale-frac), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert string to int, %w", err)
+		}
+		if num < 0 {
+			return nil, fmt.Errorf("negative number %s", f)
+		}
+		nums[i] = num
+	}
+
+	return nums, nil
+}
+
+type ruleParser struct {
+	tokens []string
+	games  []WeightedGame
+	pos    int
+}
+
+func parseRule(expr string, games []WeightedGame) (*ruleNode, error) {
+	p := &ruleParser{tokens: tokenizeRule(expr), games: games}
+	node, err := p.union()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in rule", p.tokens[p.pos])
+	}
+
+	return node, nil
+}
+
+func tokenizeRule(expr string) []string {
+	var tokens []string
+	start := -1
+	for i, c := range expr {
+		switch c {
+		case '&', '|', '(', ')', ' ', '\t':
+			if start >= 0 {
+				tokens = append(tokens, expr[start:i])
+				start = -1
+			}
+			if c != ' ' && c != '\t' {
+				tokens = append(tokens, string(c))
+			}
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, expr[start:])
+	}
+
+	return tokens
+}
+
+func (p *ruleParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// union parses "intersection { '|' intersection }".
+func (p *ruleParser) union() (*ruleNode, error) {
+	left, err := p.intersection()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "|" {
+		p.pos++
+		right, err := p.intersection()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleNode{op: '|', left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// intersection parses "operand { '&' operand }".
+func (p *ruleParser) intersection() (*ruleNode, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&" {
+		p.pos++
+		right, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleNode{op: '&', left: left, right: right}
+	}
+
+	return left, nil
+}
+
+// operand parses "NAME | '(' union ')'".
+func (p *ruleParser) operand() (*ruleNode, error) {
+	tok := p.peek()
+	p.pos++
+	switch tok {
+	case "":
+		return nil, errors.New("unexpected end of rule")
+	case "(":
+		node, err := p.union()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing ')' in rule")
+		}
+		p.pos++
+		return node, nil
+	case "&", "|", ")":
+		return nil, fmt.Errorf("unexpected %q in rule", tok)
+	}
+	for i := range p.games {
+		if p.games[i].Name == tok {
+			return &ruleNode{game: i}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown game %q in rule", tok)
+}
+
+// VotingPower returns the Shapley–Shubik and the normalized Banzhaf indices of vg.
+//
+// Coalitions are counted by size and by the vector of their weights in every component game
+// (a multi-dimensional generating function), so the work depends on the number of distinct
+// weight vectors rather than on 2^n. The vector is packed into one int64 key in mixed radix.
+// The counts without player i are obtained by dividing the generating function by (1 + x·z^w_i).
+func VotingPower(vg *VotingGame) (ss, bz map[string]float64, err error) {
+	n, m := len(vg.Players), len(vg.Games)
+	if n > 62 {
+		return nil, nil, fmt.Errorf("too many players to count coalitions, %d", n)
+	}
+
+	for _, g := range vg.Games {
+		if len(g.Weights) != n {
+			return nil, nil, fmt.Errorf("game %q has %d weights of %d players", g.Name, len(g.Weights), n)
+		}
+	}
+
+	radix := make([]int64, m)
+	totals := make([]int64, m)
+	offsets := make([]int64, n) // key of the weight vector of every player
+	place := int64(1)
+	for j, g := range vg.Games {
+		for _, w := range g.Weights {
+			totals[j] += w
+		}
+		if place > math.MaxInt64/(totals[j]+1) {
+			return nil, nil, errors.New("weights are too large to count coalitions")
+		}
+		radix[j] = place
+		place *= totals[j] + 1
+		for i, w := range g.Weights {
+			offsets[i] += w * radix[j]
+		}
+	}
+
+	sums := make([]int64, m)
+	decode := func(key int64) []int64 {
+		for j := range sums {
+			sums[j] = key / radix[j] % (totals[j] + 1)
+		}
+		return sums
+	}
+	winning := make(map[int64]bool)
+	wins := func(key int64) bool {
+		win, ok := winning[key]
+		if !ok {
+			win = vg.rule.wins(decode(key), vg.Games)
+			winning[key] = win
+		}
+		return win
+	}
+
+	all := make([]map[int64]int64, n+1)
+	for k := range all {
+		all[k] = make(map[int64]int64)
+	}
+	all[0][0] = 1
+	for i := 0; i < n; i++ {
+		for k := i; k >= 0; k-- {
+			for key, c := range all[k] {
+				all[k+1][key+offsets[i]] += c
+			}
+		}
+	}
+
+	weight := makeWeight(n, 0)
+	ss = make(map[string]float64, n)
+	swings := make([]float64, n)
+	var totalSwings float64
+	for i, player := range vg.Players {
+		fits := func(key int64) bool {
+			for j, s := range decode(key) {
+				if s < vg.Games[j].Weights[i] {
+					return false
+				}
+			}
+			return true
+		}
+
+		var value float64
+		without := make(map[int64]int64)
+		for k := 0; k < n; k++ {
+			prev := without
+			without = make(map[int64]int64, len(all[k]))
+			for key, c := range all[k] {
+				if k > 0 && fits(key) {
+					c -= prev[key-offsets[i]]
+				}
+				if c == 0 {
+					continue
+				}
+				without[key] = c
+				if wins(key+offsets[i]) && !wins(key) {
+					// Weight = k!(n-k-1)!/n!
+					value += weight(k) * float64(c)
+					swings[i] += float64(c)
+				}
+			}
+		}
+		ss[player] = value
+		totalSwings += swings[i]
+	}
+
+	bz = make(map[string]float64, n)
+	for i, player := range vg.Players {
+		if totalSwings > 0 {
+			bz[player] = swings[i] / totalSwings
+		} else {
+			bz[player] = 0
+		}
+	}
+
+	return ss, bz, nil
+}
@@ -0,0 +1,146 @@
+package shapley
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const votingData = `
+# double majority with a veto-proof alternative
+players A B C D E
+game states 3 1 1 1 1 1
+game population 60 35 25 20 12 8
+game super 90 35 25 20 12 8
+rule (states & population) | super
+`
+
+// bruteWorths enumerates all coalitions of vg and sets the worth of winning ones to 1.
+func bruteWorths(vg *VotingGame) (bitset []Coalition, worths map[Coalition]float64) {
+	n := len(vg.Players)
+	bitset = make([]Coalition, n)
+	for i := range bitset {
+		bitset[i] = 1 << i
+	}
+	worths = make(map[Coalition]float64, 1<<n)
+	sums := make([]int64, len(vg.Games))
+	for S := 1; S < 1<<n; S++ {
+		for j, g := range vg.Games {
+			sums[j] = 0
+			for i, w := range g.Weights {
+				if S&(1<<i) != 0 {
+					sums[j] += w
+				}
+			}
+		}
+		if vg.rule.wins(sums, vg.Games) {
+			worths[Coalition(S)] = 1
+		}
+	}
+
+	return bitset, worths
+}
+
+// bruteBanzhaf counts the swings of every player in the worths of bruteWorths and normalizes them.
+func bruteBanzhaf(players []string, worths map[Coalition]float64) map[string]float64 {
+	swings := make([]float64, len(players))
+	var total float64
+	for i := range players {
+		bit := Singleton(i)
+		for S := Coalition(0); S < Grand(len(players)); S++ {
+			if !S.Has(i) && worths[S|bit] == 1 && worths[S] == 0 {
+				swings[i]++
+				total++
+			}
+		}
+	}
+	bz := make(map[string]float64, len(players))
+	for i, player := range players {
+		bz[player] = swings[i] / total
+	}
+
+	return bz
+}
+
+func Test_votingPower(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   string
+		wantSS map[string]float64
+		wantBz map[string]float64
+	}{
+		{
+			name:   "weighted",
+			spec:   "players A B C\ngame g 3 2 1 1",
+			wantSS: map[string]float64{"A": 2. / 3, "B": 1. / 6, "C": 1. / 6},
+			wantBz: map[string]float64{"A": 0.6, "B": 0.2, "C": 0.2},
+		},
+		{
+			name:   "decimals",
+			spec:   "players A B C\ngame g 1.5 1 0.5 0.5",
+			wantSS: map[string]float64{"A": 2. / 3, "B": 1. / 6, "C": 1. / 6},
+			wantBz: map[string]float64{"A": 0.6, "B": 0.2, "C": 0.2},
+		},
+		{
+			name: "composite",
+			spec: votingData,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vg, err := ParseVoting(strings.NewReader(tt.spec))
+			if err != nil {
+				t.Fatalf("ParseVoting() error = %v", err)
+			}
+			gotSS, gotBz, err := VotingPower(vg)
+			if err != nil {
+				t.Fatalf("VotingPower() error = %v", err)
+			}
+			if tt.wantSS == nil {
+				bitset, worths := bruteWorths(vg)
+				tt.wantSS, _ = shapley(vg.Players, bitset, worths)
+				tt.wantBz = bruteBanzhaf(vg.Players, worths)
+			}
+			for key, value := range gotSS {
+				if wantValue := tt.wantSS[key]; math.Abs(wantValue-value) > 1e-9 {
+					t.Errorf("%s: wantSS = %v, gotSS = %v", key, wantValue, value)
+				}
+			}
+			for key, wantValue := range tt.wantBz {
+				if value := gotBz[key]; math.Abs(wantValue-value) > 1e-9 {
+					t.Errorf("%s: wantBz = %v, gotBz = %v", key, wantValue, value)
+				}
+			}
+		})
+	}
+}
+
+func Test_VotingPowerWeights(t *testing.T) {
+	vg := &VotingGame{Players: []string{"A", "B", "C"}, Games: []WeightedGame{{Name: "g", Quota: 2, Weights: []int64{1, 1}}}}
+	if _, _, err := VotingPower(vg); err == nil {
+		t.Errorf("VotingPower() of 2 weights of 3 players error = nil, want error")
+	}
+}
+
+func Test_parseVoting(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{name: "no games", spec: "players A B"},
+		{name: "weights count", spec: "players A B\ngame g 2 1"},
+		{name: "negative", spec: "players A B\ngame g 2 -1 1"},
+		{name: "unknown game", spec: "players A B\ngame g 2 1 1\nrule g & h"},
+		{name: "unbalanced", spec: "players A B\ngame g 2 1 1\nrule (g"},
+		{name: "directive", spec: "voters A B"},
+		{name: "players twice", spec: "players A B\ngame g 2 1 1\nplayers A B C"},
+		{name: "game twice", spec: "players A B\ngame g 2 1 1\ngame g 1 1 1\nrule g"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseVoting(strings.NewReader(tt.spec)); err == nil {
+				t.Errorf("ParseVoting() error = nil, want error")
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package shapley
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_shapleyWeights(t *testing.T) {
+	tests := []struct {
+		name string
+		want []float64
+		n    int
+		prec uint
+	}{
+		{name: "log", n: 3, want: []float64{1. / 3, 1. / 6, 1. / 3}},
+		{name: "big", n: 4, prec: 64, want: []float64{0.25, 1. / 12, 1. / 12, 0.25}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shapleyWeights(tt.n, tt.prec)
+			for k, want := range tt.want {
+				if math.Abs(got[k]-want) > 1e-15 {
+					t.Errorf("shapleyWeights(%d)[%d] = %v, want %v", tt.n, k, got[k], want)
+				}
+			}
+		})
+	}
+}
+
+func Test_shapleyWeightsAccuracy(t *testing.T) {
+	for _, n := range []int{2, 9, 13, 33, 64, 100, 333, 1000} {
+		bound := (lgamma(n+1) + 1) * math.Ldexp(1, -50)
+		got := shapleyWeights(n, 0)
+		for k, w := range bigWeights(n, 256) {
+			want, _ := w.Float64()
+			if rel := math.Abs(got[k]-want) / want; rel > bound {
+				t.Errorf("n = %d, k = %d: relative error %g exceeds %g", n, k, rel, bound)
+			}
+		}
+	}
+}